@@ -0,0 +1,192 @@
+// Command eval runs a golden set of questions through the RAG pipeline and
+// reports retrieval and answer quality, or compares two such runs.
+//
+//	eval run -golden golden.jsonl -out runs/baseline.json
+//	eval diff runs/baseline.json runs/candidate.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/eval"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"github.com/pdf-rag-system/backend/internal/service"
+	"github.com/pdf-rag-system/backend/pkg/config"
+	"github.com/pdf-rag-system/backend/pkg/database"
+)
+
+const usage = `usage:
+  eval run  -golden <file.jsonl> [-out run.json] [-label name] [-k 5] [-mode vector|keyword|hybrid] [-vector-weight w] [-retrieval-only] [-workspace id]
+  eval diff [-max-drop 0.02] [-max-latency-increase 0.25] <base.json> <head.json>
+
+diff exits with status 1 when a metric regressed beyond the thresholds.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = runCommand(os.Args[2:])
+	case "diff":
+		var regressed bool
+		regressed, err = diffCommand(os.Args[2:])
+		if err == nil && regressed {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("eval %s failed: %v", os.Args[1], err)
+	}
+}
+
+func runCommand(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	goldenPath := flags.String("golden", "", "golden set (JSONL)")
+	outPath := flags.String("out", "", "write the run as JSON to this file")
+	label := flags.String("label", "", "name of the run (defaults to the output file name)")
+	k := flags.Int("k", 5, "cutoff for recall@k")
+	mode := flags.String("mode", "", "search mode override: vector, keyword or hybrid")
+	vectorWeight := flags.Float64("vector-weight", -1, "hybrid vector weight override (0-1)")
+	retrievalOnly := flags.Bool("retrieval-only", false, "skip answer generation (no LLM calls)")
+	workspace := flags.String("workspace", domain.DefaultWorkspaceID, "workspace whose documents are searched")
+	flags.Parse(args)
+
+	if *goldenPath == "" {
+		return fmt.Errorf("-golden is required\n%s", usage)
+	}
+	if *mode != "" && !service.ValidSearchMode(*mode) {
+		return fmt.Errorf("invalid search mode %q", *mode)
+	}
+
+	opts := eval.Options{
+		Label:       *label,
+		K:           *k,
+		SearchMode:  *mode,
+		SkipAnswers: *retrievalOnly,
+		Workspace:   *workspace,
+	}
+	if opts.Label == "" {
+		opts.Label = *outPath
+	}
+	if *vectorWeight >= 0 {
+		if *vectorWeight > 1 {
+			return fmt.Errorf("vector weight must be between 0 and 1")
+		}
+		opts.VectorWeight = vectorWeight
+	}
+
+	questions, err := eval.LoadGolden(*goldenPath)
+	if err != nil {
+		return err
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+	cfg := config.Load()
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	documentRepo := repository.NewDocumentRepository(db)
+	chunkRepo := repository.NewChunkRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	embeddingCacheRepo := repository.NewEmbeddingCacheRepository(db)
+	embeddingSpaceRepo := repository.NewEmbeddingSpaceRepository(db)
+
+	httpClient := service.NewHTTPClient(cfg)
+	llm, err := service.NewChatProvider(cfg, httpClient)
+	if err != nil {
+		return err
+	}
+	embedder, err := service.NewEmbedder(cfg, httpClient)
+	if err != nil {
+		return err
+	}
+
+	embeddingService := service.NewEmbeddingService(embeddingCacheRepo, embedder, cfg)
+	sessionService := service.NewSessionService(sessionRepo)
+	collectionService := service.NewCollectionService(repository.NewCollectionRepository(db), documentRepo)
+	reranker, err := service.NewReranker(cfg, llm, httpClient)
+	if err != nil {
+		return err
+	}
+	grounding, err := service.NewGroundednessChecker(cfg, llm, httpClient)
+	if err != nil {
+		return err
+	}
+	// No history repository: evaluation queries are not recorded in analytics
+	chatService := service.NewChatService(chunkRepo, embeddingSpaceRepo, nil, sessionService, collectionService, embeddingService, llm, reranker, grounding, cfg)
+
+	ctx := context.Background()
+
+	// Questions without document_ids search every indexed document of the workspace
+	docs, err := documentRepo.List(ctx, opts.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+	var docIDs []string
+	for _, doc := range docs {
+		if doc.Status == domain.StatusCompleted || doc.Status == domain.StatusPartial {
+			docIDs = append(docIDs, doc.ID)
+		}
+	}
+
+	runner := eval.NewRunner(chatService, sessionService, cfg, docIDs)
+	run := runner.Run(ctx, questions, opts)
+
+	fmt.Println()
+	eval.PrintSummary(os.Stdout, run)
+
+	if *outPath != "" {
+		if err := eval.SaveRun(*outPath, run); err != nil {
+			return fmt.Errorf("failed to write run: %w", err)
+		}
+		fmt.Printf("\nRun written to %s\n", *outPath)
+	}
+	return nil
+}
+
+func diffCommand(args []string) (bool, error) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	maxDrop := flags.Float64("max-drop", 0.02, "largest allowed drop of recall, MRR, precision or keyword hit rate")
+	maxLatency := flags.Float64("max-latency-increase", 0.25, "largest allowed relative increase of p95 latency")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return false, fmt.Errorf("diff needs a base and a head run\n%s", usage)
+	}
+
+	base, err := eval.LoadRun(flags.Arg(0))
+	if err != nil {
+		return false, err
+	}
+	head, err := eval.LoadRun(flags.Arg(1))
+	if err != nil {
+		return false, err
+	}
+
+	diff := eval.Compare(base, head, eval.Thresholds{Quality: *maxDrop, Latency: *maxLatency})
+	eval.PrintDiff(os.Stdout, base, head, diff)
+
+	if diff.Regressed() {
+		fmt.Println("\nRegression detected")
+	}
+	return diff.Regressed(), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"github.com/pdf-rag-system/backend/internal/service"
+	"github.com/pdf-rag-system/backend/pkg/config"
+	"github.com/pdf-rag-system/backend/pkg/database"
+)
+
+const keysUsage = `usage: pdf-rag-server keys <command>
+
+commands:
+  create -name <name> [-scope read|write|admin] [-workspace id]
+                 issue a key and print it once; -workspace binds it to one workspace
+  list           list keys
+  revoke <id>    revoke a key`
+
+// runKeys handles the keys subcommand, which manages API keys directly in
+// the database, e.g. to issue the first admin key
+func runKeys(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", keysUsage)
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	workspaces := service.NewWorkspaceService(repository.NewWorkspaceRepository(db), cfg)
+	keys := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), workspaces)
+
+	ctx := context.Background()
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("keys create", flag.ContinueOnError)
+		name := flags.String("name", "", "name of the key")
+		scope := flags.String("scope", "admin", "scope of the key: read, write or admin")
+		workspace := flags.String("workspace", "", "bind the key to this workspace (default: all workspaces)")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		req := service.CreateAPIKeyRequest{Name: *name, Scope: *scope}
+		if *workspace != "" {
+			req.WorkspaceID = workspace
+		}
+		key, err := keys.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s key %q (%s) for %s\n", key.Scope, key.Name, key.ID, keyWorkspace(key.APIKey))
+		fmt.Printf("Key: %s\n", key.Key)
+		fmt.Println("Store it now; it cannot be shown again.")
+
+	case "list":
+		list, err := keys.List(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, key := range list {
+			status := "active"
+			if key.Revoked() {
+				status = "revoked " + key.RevokedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s  %-12s %-5s  %-24s %-36s  %s\n", key.ID, key.Prefix, key.Scope, key.Name, keyWorkspace(key), status)
+		}
+
+	case "revoke":
+		if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+			return fmt.Errorf("missing key id\n%s", keysUsage)
+		}
+		if err := keys.Revoke(ctx, nil, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked key %s\n", args[1])
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], keysUsage)
+	}
+
+	return nil
+}
+
+// keyWorkspace describes which workspaces a key may act in
+func keyWorkspace(key *domain.APIKey) string {
+	if key.WorkspaceID == nil {
+		return "all workspaces"
+	}
+	return "workspace " + *key.WorkspaceID
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/pdf-rag-system/backend/internal/api"
 	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/internal/domain"
 	"github.com/pdf-rag-system/backend/internal/repository"
 	"github.com/pdf-rag-system/backend/internal/service"
 	"github.com/pdf-rag-system/backend/pkg/config"
@@ -25,6 +27,22 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// pdf-rag-server migrate <command> manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg.Database, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// pdf-rag-server keys <command> manages API keys and exits
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeys(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("API key command failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize database
 	db, err := database.InitDB(cfg.Database)
 	if err != nil {
@@ -41,14 +59,84 @@ func main() {
 	// Initialize repositories
 	documentRepo := repository.NewDocumentRepository(db)
 	chunkRepo := repository.NewChunkRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	embeddingCacheRepo := repository.NewEmbeddingCacheRepository(db)
+	embeddingSpaceRepo := repository.NewEmbeddingSpaceRepository(db)
+	queryHistoryRepo := repository.NewQueryHistoryRepository(db)
+	feedbackRepo := repository.NewFeedbackRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	collectionRepo := repository.NewCollectionRepository(db)
+
+	// Initialize LLM and embedding API clients
+	httpClient := service.NewHTTPClient(cfg)
+	llm, err := service.NewChatProvider(cfg, httpClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize chat provider: %v", err)
+	}
+	embedder, err := service.NewEmbedder(cfg, httpClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize embedder: %v", err)
+	}
 
 	// Initialize services
-	documentService := service.NewDocumentService(documentRepo, chunkRepo, docreaderClient, cfg)
-	chatService := service.NewChatService(chunkRepo, cfg)
+	embeddingService := service.NewEmbeddingService(embeddingCacheRepo, embedder, cfg)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, cfg)
+	documentService := service.NewDocumentService(documentRepo, chunkRepo, jobRepo, embeddingSpaceRepo, workspaceService, docreaderClient, embeddingService, cfg)
+	embeddingSpaceService := service.NewEmbeddingSpaceService(embeddingSpaceRepo, documentService, embeddingService, cfg)
+	sessionService := service.NewSessionService(sessionRepo)
+	collectionService := service.NewCollectionService(collectionRepo, documentRepo)
+	reranker, err := service.NewReranker(cfg, llm, httpClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize reranker: %v", err)
+	}
+	grounding, err := service.NewGroundednessChecker(cfg, llm, httpClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize groundedness checker: %v", err)
+	}
+	analyticsService := service.NewAnalyticsService(queryHistoryRepo)
+	feedbackService := service.NewFeedbackService(queryHistoryRepo, feedbackRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, workspaceService)
+	rateLimiter := service.NewRateLimiter(cfg)
+	chatService := service.NewChatService(chunkRepo, embeddingSpaceRepo, queryHistoryRepo, sessionService, collectionService, embeddingService, llm, reranker, grounding, cfg)
+
+	// Make sure an embedding space exists before anything is embedded
+	if err := embeddingSpaceService.Init(context.Background()); err != nil {
+		log.Fatalf("Failed to initialize embedding spaces: %v", err)
+	}
+
+	// Start ingestion workers
+	ingestionWorker := service.NewIngestionWorker(jobRepo, documentService, cfg)
+	if err := ingestionWorker.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start ingestion workers: %v", err)
+	}
+	defer ingestionWorker.Stop()
 
 	// Initialize handlers
 	documentHandler := api.NewDocumentHandler(documentService)
 	chatHandler := api.NewChatHandler(chatService)
+	sessionHandler := api.NewSessionHandler(sessionService)
+	embeddingHandler := api.NewEmbeddingHandler(embeddingService)
+	embeddingSpaceHandler := api.NewEmbeddingSpaceHandler(embeddingSpaceService)
+	analyticsHandler := api.NewAnalyticsHandler(analyticsService)
+	feedbackHandler := api.NewFeedbackHandler(feedbackService)
+	apiKeyHandler := api.NewAPIKeyHandler(apiKeyService)
+	workspaceHandler := api.NewWorkspaceHandler(workspaceService)
+	collectionHandler := api.NewCollectionHandler(collectionService)
+
+	// Authentication and rate limits
+	auth := api.NewAuth(apiKeyService, workspaceService, rateLimiter, cfg.Auth.Enabled)
+	if !cfg.Auth.Enabled {
+		log.Println("WARNING: API key authentication is disabled (AUTH_ENABLED=false)")
+	}
+	read := auth.Require(domain.ScopeRead)
+	write := auth.Require(domain.ScopeWrite)
+	admin := auth.Require(domain.ScopeAdmin)
+	// global is for admin operations across workspaces
+	global := auth.Global()
+	queryLimit := auth.RateLimit(service.BudgetQueries)
+	uploadLimit := auth.RateLimit(service.BudgetUploads)
 
 	// Setup router
 	router := gin.Default()
@@ -59,8 +147,8 @@ func main() {
 	// CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Workspace-ID"},
 		AllowCredentials: true,
 	}))
 
@@ -70,23 +158,95 @@ func main() {
 	})
 
 	// API routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", auth.Authenticate())
 	{
 		// Document routes
 		docs := v1.Group("/documents")
 		{
-			docs.POST("/upload", documentHandler.Upload)
-			docs.GET("", documentHandler.List)
-			docs.GET("/:id", documentHandler.Get)
-			docs.GET("/:id/file", documentHandler.GetFile)
-			docs.GET("/:id/page/:page/image", documentHandler.GetPageImage)
-			docs.DELETE("/:id", documentHandler.Delete)
+			docs.POST("/upload", write, uploadLimit, documentHandler.Upload)
+			docs.GET("", read, documentHandler.List)
+			docs.POST("/reindex", admin, documentHandler.ReindexAll)
+			docs.GET("/:id", read, documentHandler.Get)
+			docs.PATCH("/:id", write, documentHandler.Update)
+			docs.GET("/:id/progress", read, documentHandler.Progress)
+			docs.GET("/:id/progress/stream", read, documentHandler.ProgressStream)
+			docs.GET("/:id/failed-chunks", read, documentHandler.FailedChunks)
+			docs.POST("/:id/retry-failed", write, documentHandler.RetryFailed)
+			docs.POST("/:id/reindex", write, documentHandler.Reindex)
+			docs.GET("/:id/file", read, documentHandler.GetFile)
+			docs.GET("/:id/page/:page/image", read, documentHandler.GetPageImage)
+			docs.DELETE("/:id", write, documentHandler.Delete)
 		}
 
 		// Chat routes
 		chat := v1.Group("/chat")
 		{
-			chat.POST("/query", chatHandler.Query)
+			chat.POST("/query", read, queryLimit, chatHandler.Query)
+			chat.POST("/stream", read, queryLimit, chatHandler.Stream)
+		}
+
+		// Session routes
+		sessions := v1.Group("/sessions")
+		{
+			sessions.GET("", read, sessionHandler.List)
+			sessions.GET("/:id", read, sessionHandler.Get)
+			sessions.DELETE("/:id", write, sessionHandler.Delete)
+		}
+
+		// Collection routes
+		collections := v1.Group("/collections")
+		{
+			collections.GET("", read, collectionHandler.List)
+			collections.POST("", write, collectionHandler.Create)
+			collections.GET("/:id", read, collectionHandler.Get)
+			collections.PUT("/:id", write, collectionHandler.Update)
+			collections.DELETE("/:id", write, collectionHandler.Delete)
+			collections.POST("/:id/documents", write, collectionHandler.AddDocuments)
+			collections.DELETE("/:id/documents", write, collectionHandler.RemoveDocuments)
+		}
+
+		// Embedding routes
+		v1.GET("/embeddings/cache/stats", read, embeddingHandler.CacheStats)
+
+		spaces := v1.Group("/embedding-spaces")
+		{
+			spaces.GET("", read, embeddingSpaceHandler.List)
+			spaces.POST("", admin, global, embeddingSpaceHandler.Create)
+			spaces.POST("/:id/build", admin, global, embeddingSpaceHandler.Build)
+			spaces.POST("/:id/activate", admin, global, embeddingSpaceHandler.Activate)
+			spaces.DELETE("/:id", admin, global, embeddingSpaceHandler.Delete)
+		}
+
+		// Analytics routes
+		analytics := v1.Group("/analytics", admin)
+		{
+			analytics.GET("/top-queries", analyticsHandler.TopQueries)
+			analytics.GET("/zero-result-queries", analyticsHandler.ZeroResultQueries)
+			analytics.GET("/latency", analyticsHandler.Latency)
+			analytics.GET("/top-documents", analyticsHandler.TopDocuments)
+		}
+
+		// Feedback routes
+		v1.POST("/queries/:id/feedback", read, feedbackHandler.Submit)
+		v1.GET("/queries/:id/feedback", read, feedbackHandler.Get)
+		v1.GET("/feedback/export", admin, feedbackHandler.Export)
+
+		// Workspace routes
+		workspaces := v1.Group("/workspaces", admin)
+		{
+			workspaces.POST("", global, workspaceHandler.Create)
+			workspaces.GET("", global, workspaceHandler.List)
+			workspaces.GET("/:id", workspaceHandler.Get)
+			workspaces.PUT("/:id", global, workspaceHandler.Update)
+			workspaces.DELETE("/:id", global, workspaceHandler.Delete)
+		}
+
+		// API key administration
+		keys := v1.Group("/admin/api-keys", admin)
+		{
+			keys.POST("", apiKeyHandler.Create)
+			keys.GET("", apiKeyHandler.List)
+			keys.DELETE("/:id", apiKeyHandler.Revoke)
 		}
 	}
 
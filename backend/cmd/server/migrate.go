@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pdf-rag-system/backend/pkg/config"
+	"github.com/pdf-rag-system/backend/pkg/database"
+)
+
+const migrateUsage = `usage: pdf-rag-server migrate <command>
+
+commands:
+  status          list migrations and whether they are applied
+  up              apply all pending migrations
+  down [n]        revert the last n applied migrations (default 1)
+  to <version>    migrate up or down to the given version`
+
+// runMigrate handles the migrate subcommand
+func runMigrate(cfg config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.Applied {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d  %-24s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		if err := migrator.Down(ctx, steps); err != nil {
+			return err
+		}
+
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version\n%s", migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.To(ctx, version); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema is at version %d (latest %d)\n", version, migrator.Latest())
+	return nil
+}
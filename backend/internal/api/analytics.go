@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type AnalyticsHandler struct {
+	service *service.AnalyticsService
+}
+
+func NewAnalyticsHandler(service *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service}
+}
+
+// TopQueries returns the most frequently asked queries
+func (h *AnalyticsHandler) TopQueries(c *gin.Context) {
+	window, ok := bindAnalyticsWindow(c)
+	if !ok {
+		return
+	}
+
+	queries, err := h.service.TopQueries(c.Request.Context(), currentWorkspace(c), window)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    queries,
+	})
+}
+
+// ZeroResultQueries returns the most frequent queries without relevant results
+func (h *AnalyticsHandler) ZeroResultQueries(c *gin.Context) {
+	window, ok := bindAnalyticsWindow(c)
+	if !ok {
+		return
+	}
+
+	queries, err := h.service.ZeroResultQueries(c.Request.Context(), currentWorkspace(c), window)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    queries,
+	})
+}
+
+// Latency returns p50/p95 latency overall and per stage
+func (h *AnalyticsHandler) Latency(c *gin.Context) {
+	window, ok := bindAnalyticsWindow(c)
+	if !ok {
+		return
+	}
+
+	latency, err := h.service.Latency(c.Request.Context(), currentWorkspace(c), window)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    latency,
+	})
+}
+
+// TopDocuments returns the documents cited most often
+func (h *AnalyticsHandler) TopDocuments(c *gin.Context) {
+	window, ok := bindAnalyticsWindow(c)
+	if !ok {
+		return
+	}
+
+	docs, err := h.service.TopDocuments(c.Request.Context(), currentWorkspace(c), window)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    docs,
+	})
+}
+
+// bindAnalyticsWindow reads the optional days and limit query parameters
+func bindAnalyticsWindow(c *gin.Context) (service.AnalyticsWindow, bool) {
+	var window service.AnalyticsWindow
+
+	for name, dest := range map[string]*int{"days": &window.Days, "limit": &window.Limit} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a positive integer"})
+			return window, false
+		}
+		*dest = value
+	}
+
+	return window, true
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// Create issues a key. The response is the only time the key itself is
+// returned. Keys bound to a workspace can only issue keys for it.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req service.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if workspaceID := boundWorkspace(c); workspaceID != nil {
+		if req.WorkspaceID != nil && *req.WorkspaceID != *workspaceID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is bound to workspace " + *workspaceID})
+			return
+		}
+		req.WorkspaceID = workspaceID
+	}
+
+	key, err := h.service.Create(c.Request.Context(), req)
+	if errors.Is(err, service.ErrInvalidAPIKeyRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to create API key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// List returns the keys without their secrets; keys bound to a workspace
+// only see the keys of that workspace
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context(), boundWorkspace(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// Revoke disables a key
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.Revoke(c.Request.Context(), boundWorkspace(c), id)
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to revoke API key %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "API key revoked",
+	})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+const (
+	// apiKeyContextKey is where the authenticated key is kept on the gin context
+	apiKeyContextKey = "apiKey"
+	// workspaceContextKey is where the workspace the request acts in is kept
+	workspaceContextKey = "workspaceID"
+	// workspaceHeader selects the workspace for keys not bound to one
+	workspaceHeader = "X-Workspace-ID"
+)
+
+// Auth provides the middleware that authenticates API keys, checks their
+// scope, selects the workspace and applies rate limits. With authentication
+// disabled every request is allowed, the workspace comes from the
+// X-Workspace-ID header and rate limits apply per client IP.
+type Auth struct {
+	keys       *service.APIKeyService
+	workspaces *service.WorkspaceService
+	limiter    *service.RateLimiter
+	enabled    bool
+}
+
+func NewAuth(keys *service.APIKeyService, workspaces *service.WorkspaceService, limiter *service.RateLimiter, enabled bool) *Auth {
+	return &Auth{
+		keys:       keys,
+		workspaces: workspaces,
+		limiter:    limiter,
+		enabled:    enabled,
+	}
+}
+
+// Authenticate checks the key in an Authorization: Bearer header. Requests
+// without one pass on unauthenticated and are turned away by Require.
+func (a *Auth) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !a.enabled || header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, key, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header must be Bearer <api key>"})
+			return
+		}
+		a.authenticate(c, strings.TrimSpace(key))
+	}
+}
+
+func (a *Auth) authenticate(c *gin.Context, key string) {
+	apiKey, err := a.keys.Authenticate(c.Request.Context(), key)
+	if errors.Is(err, service.ErrInvalidAPIKey) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to authenticate API key: %v", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(apiKeyContextKey, apiKey)
+	c.Next()
+}
+
+// Require only lets requests through whose key has at least the given
+// scope, then selects the workspace the request acts in: the one the key is
+// bound to, or else the one named by the X-Workspace-ID header, defaulting
+// to the default workspace
+func (a *Auth) Require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := currentAPIKey(c)
+		if a.enabled {
+			if apiKey == nil {
+				c.Header("WWW-Authenticate", "Bearer")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+				return
+			}
+			if !apiKey.Allows(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key scope " + apiKey.Scope + " does not allow this request; " + scope + " required"})
+				return
+			}
+		}
+
+		requested := strings.TrimSpace(c.GetHeader(workspaceHeader))
+		workspaceID := domain.DefaultWorkspaceID
+		if apiKey != nil && apiKey.WorkspaceID != nil {
+			if requested != "" && requested != *apiKey.WorkspaceID {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key is bound to workspace " + *apiKey.WorkspaceID})
+				return
+			}
+			workspaceID = *apiKey.WorkspaceID
+		} else if requested != "" {
+			exists, err := a.workspaces.Exists(c.Request.Context(), requested)
+			if err != nil {
+				log.Printf("ERROR: Failed to look up workspace %s: %v", requested, err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if !exists {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+				return
+			}
+			workspaceID = requested
+		}
+
+		c.Set(workspaceContextKey, workspaceID)
+		c.Next()
+	}
+}
+
+// Global turns away keys bound to a workspace, for operations that span
+// workspaces such as managing them or the embedding spaces
+func (a *Auth) Global() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if boundWorkspace(c) != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key is bound to a workspace; this request needs a key for all workspaces"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimit takes a token from the client's bucket for budget, answering
+// 429 with Retry-After once it is empty
+func (a *Auth) RateLimit(budget string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		var perMinute *int
+		if apiKey := currentAPIKey(c); apiKey != nil {
+			client = "key:" + apiKey.ID
+			switch budget {
+			case service.BudgetQueries:
+				perMinute = apiKey.QueryRateLimit
+			case service.BudgetUploads:
+				perMinute = apiKey.UploadRateLimit
+			}
+		}
+
+		limit := a.limiter.Take(client, budget, perMinute)
+		if limit.Limit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+		}
+		if !limit.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limit.RetryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded for " + budget})
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentAPIKey returns the key the request was authenticated with, if any
+func currentAPIKey(c *gin.Context) *domain.APIKey {
+	value, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil
+	}
+	apiKey, _ := value.(*domain.APIKey)
+	return apiKey
+}
+
+// boundWorkspace returns the workspace the request's key is bound to, or
+// nil when the key may act in any workspace
+func boundWorkspace(c *gin.Context) *string {
+	if apiKey := currentAPIKey(c); apiKey != nil {
+		return apiKey.WorkspaceID
+	}
+	return nil
+}
+
+// currentWorkspace returns the workspace selected for the request by Require
+func currentWorkspace(c *gin.Context) string {
+	if workspaceID := c.GetString(workspaceContextKey); workspaceID != "" {
+		return workspaceID
+	}
+	return domain.DefaultWorkspaceID
+}
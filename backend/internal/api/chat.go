@@ -1,9 +1,13 @@
 package api
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/internal/domain"
 	"github.com/pdf-rag-system/backend/internal/service"
 )
 
@@ -16,32 +20,133 @@ func NewChatHandler(service *service.ChatService) *ChatHandler {
 }
 
 func (h *ChatHandler) Query(c *gin.Context) {
-	var req service.QueryRequest
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	req, ok := bindQueryRequest(c)
+	if !ok {
 		return
 	}
 
-	if req.Query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Query cannot be empty"})
+	resp, err := h.service.Query(c.Request.Context(), req)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 		return
 	}
-
-	if len(req.DocumentIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document IDs required"})
+	if errors.Is(err, service.ErrCollectionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+	if errors.Is(err, client.ErrCircuitOpen) {
+		// A provider kept failing; tell clients to come back later
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
 		return
 	}
-
-	resp, err := h.service.Query(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"answer":  resp.Answer,
-		"citations": resp.Citations,
+		"success":         true,
+		"answer":          resp.Answer,
+		"citations":       resp.Citations,
+		"session_id":      resp.SessionID,
+		"session_saved":   resp.SessionSaved,
+		"rewritten_query": resp.RewrittenQuery,
+		"query_id":        resp.QueryID,
+		"markers":         resp.Markers,
+		"invalid_sources": resp.InvalidSources,
+		"uncited":         resp.Uncited,
+		"groundedness":    resp.Groundedness,
+	})
+}
+
+// Stream answers a query over Server-Sent Events. It emits a "citations"
+// event with the retrieved chunks, one "token" event per answer delta and a
+// final "done" (or "error") event. The "done" event carries the answer with
+// normalized source markers, only the cited chunks and the groundedness
+// verdict; if the answer was rejected it replaces the streamed text. The
+// request context is cancelled when the client disconnects, which aborts the
+// upstream LLM call.
+func (h *ChatHandler) Stream(c *gin.Context) {
+	req, ok := bindQueryRequest(c)
+	if !ok {
+		return
+	}
+
+	startEventStream(c)
+	ctx := c.Request.Context()
+
+	resp, err := h.service.QueryStream(ctx, req, service.StreamCallbacks{
+		OnCitations: func(citations []*domain.SearchResult) error {
+			return sendEvent(c, "citations", gin.H{"citations": citations})
+		},
+		OnToken: func(token string) error {
+			return sendEvent(c, "token", gin.H{"content": token})
+		},
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("Chat stream cancelled by client: %v", ctx.Err())
+			return
+		}
+		sendEvent(c, "error", gin.H{"error": err.Error()})
+		return
+	}
+
+	sendEvent(c, "done", gin.H{
+		"success":         true,
+		"answer":          resp.Answer,
+		"citations":       resp.Citations,
+		"session_id":      resp.SessionID,
+		"session_saved":   resp.SessionSaved,
+		"rewritten_query": resp.RewrittenQuery,
+		"query_id":        resp.QueryID,
+		"markers":         resp.Markers,
+		"invalid_sources": resp.InvalidSources,
+		"uncited":         resp.Uncited,
+		"groundedness":    resp.Groundedness,
 	})
 }
+
+// bindQueryRequest parses and validates a QueryRequest, writing a 400
+// response and returning false when it is invalid.
+func bindQueryRequest(c *gin.Context) (*service.QueryRequest, bool) {
+	var req service.QueryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return nil, false
+	}
+
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query cannot be empty"})
+		return nil, false
+	}
+
+	if req.AllDocuments && (len(req.DocumentIDs) > 0 || len(req.CollectionIDs) > 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "all_documents cannot be combined with document or collection IDs"})
+		return nil, false
+	}
+
+	if !req.AllDocuments && len(req.DocumentIDs) == 0 && len(req.CollectionIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document IDs, collection IDs or all_documents required"})
+		return nil, false
+	}
+
+	if req.SearchMode != "" && !service.ValidSearchMode(req.SearchMode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search mode must be vector, keyword or hybrid"})
+		return nil, false
+	}
+
+	if req.VectorWeight != nil && (*req.VectorWeight < 0 || *req.VectorWeight > 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vector weight must be between 0 and 1"})
+		return nil, false
+	}
+
+	if err := req.Filters.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	req.WorkspaceID = currentWorkspace(c)
+	return &req, true
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type CollectionHandler struct {
+	service *service.CollectionService
+}
+
+func NewCollectionHandler(service *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+func (h *CollectionHandler) Create(c *gin.Context) {
+	var req service.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	collection, err := h.service.Create(c.Request.Context(), currentWorkspace(c), req)
+	if !h.handleError(c, err, "create collection") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    collection,
+	})
+}
+
+func (h *CollectionHandler) List(c *gin.Context) {
+	collections, err := h.service.List(c.Request.Context(), currentWorkspace(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collections,
+	})
+}
+
+// Get returns a collection with its document IDs
+func (h *CollectionHandler) Get(c *gin.Context) {
+	collection, err := h.service.Get(c.Request.Context(), currentWorkspace(c), c.Param("id"))
+	if !h.handleError(c, err, "get collection") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+	})
+}
+
+// Update replaces the name and description of a collection
+func (h *CollectionHandler) Update(c *gin.Context) {
+	var req service.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	collection, err := h.service.Update(c.Request.Context(), currentWorkspace(c), c.Param("id"), req)
+	if !h.handleError(c, err, "update collection") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+	})
+}
+
+// Delete removes a collection; its documents are kept
+func (h *CollectionHandler) Delete(c *gin.Context) {
+	err := h.service.Delete(c.Request.Context(), currentWorkspace(c), c.Param("id"))
+	if !h.handleError(c, err, "delete collection") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Collection deleted",
+	})
+}
+
+// AddDocuments adds documents to a collection
+func (h *CollectionHandler) AddDocuments(c *gin.Context) {
+	var req service.CollectionDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	collection, err := h.service.AddDocuments(c.Request.Context(), currentWorkspace(c), c.Param("id"), req.DocumentIDs)
+	if !h.handleError(c, err, "add documents to collection") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+	})
+}
+
+// RemoveDocuments removes documents from a collection
+func (h *CollectionHandler) RemoveDocuments(c *gin.Context) {
+	var req service.CollectionDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	collection, err := h.service.RemoveDocuments(c.Request.Context(), currentWorkspace(c), c.Param("id"), req.DocumentIDs)
+	if !h.handleError(c, err, "remove documents from collection") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+	})
+}
+
+// handleError writes the response for a failed collection request and
+// reports whether err was nil
+func (h *CollectionHandler) handleError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrCollectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+	case errors.Is(err, service.ErrInvalidCollection):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCollectionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("ERROR: Failed to %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return false
+}
@@ -1,8 +1,13 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pdf-rag-system/backend/internal/service"
@@ -31,37 +36,102 @@ func (h *DocumentHandler) Upload(c *gin.Context) {
 
 	log.Printf("File received: %s (size: %d bytes, %.2f MB)", header.Filename, header.Size, float64(header.Size)/(1024*1024))
 
-	doc, err := h.service.Upload(c.Request.Context(), file, header.Filename, header.Size)
+	// Identical files return the existing document unless an alias is requested
+	onDuplicate := c.DefaultPostForm("on_duplicate", service.DuplicateExisting)
+	if !service.ValidDuplicateMode(onDuplicate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "on_duplicate must be existing or alias"})
+		return
+	}
+
+	labels, ok := bindDocumentLabels(c)
+	if !ok {
+		return
+	}
+
+	doc, duplicate, err := h.service.Upload(c.Request.Context(), currentWorkspace(c), file, header.Filename, header.Size, onDuplicate, labels)
+	if errors.Is(err, service.ErrInvalidDocumentUpdate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrWorkspaceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
 	if err != nil {
 		log.Printf("ERROR: Upload service failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	log.Printf("SUCCESS: Document uploaded with ID: %s", doc.ID)
+	log.Printf("SUCCESS: Document uploaded with ID: %s (duplicate: %v)", doc.ID, duplicate)
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    doc,
+		"success":   true,
+		"data":      doc,
+		"duplicate": duplicate,
 	})
 }
 
+// bindDocumentLabels reads the optional upload form fields: tags, repeated
+// or comma-separated, and metadata as a JSON object of strings
+func bindDocumentLabels(c *gin.Context) (service.DocumentLabels, bool) {
+	var labels service.DocumentLabels
+
+	for _, value := range c.PostFormArray("tags") {
+		labels.Tags = append(labels.Tags, strings.Split(value, ",")...)
+	}
+
+	if metadata := c.PostForm("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &labels.Metadata); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "metadata must be a JSON object of strings"})
+			return labels, false
+		}
+	}
+
+	return labels, true
+}
+
+// List returns a page of documents matching the query string filters, with
+// the total count and a cursor for the next page
 func (h *DocumentHandler) List(c *gin.Context) {
-	docs, err := h.service.List(c.Request.Context())
+	var req service.DocumentListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	// Like uploads, tags may be repeated or comma-separated
+	var tags []string
+	for _, value := range req.Tags {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+	req.Tags = tags
+
+	page, err := h.service.List(c.Request.Context(), currentWorkspace(c), req)
+	if errors.Is(err, service.ErrInvalidDocumentQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
+		log.Printf("ERROR: Failed to list documents: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success":   true,
-		"documents": docs,
+		"success":     true,
+		"documents":   page.Documents,
+		"total":       page.Total,
+		"next_cursor": page.NextCursor,
 	})
 }
 
 func (h *DocumentHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 
-	doc, err := h.service.Get(c.Request.Context(), id)
+	doc, err := h.service.Get(c.Request.Context(), currentWorkspace(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
@@ -73,10 +143,226 @@ func (h *DocumentHandler) Get(c *gin.Context) {
 	})
 }
 
+// Update edits a document's title, author, creation date, tags and metadata
+func (h *DocumentHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req service.DocumentUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	doc, err := h.service.Update(c.Request.Context(), currentWorkspace(c), id, req)
+	switch {
+	case errors.Is(err, service.ErrInvalidDocumentUpdate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to update document %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    doc,
+	})
+}
+
+func (h *DocumentHandler) Progress(c *gin.Context) {
+	id := c.Param("id")
+
+	progress, err := h.service.GetProgress(c.Request.Context(), currentWorkspace(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    progress,
+	})
+}
+
+// FailedChunks lists the chunks skipped during processing and why
+func (h *DocumentHandler) FailedChunks(c *gin.Context) {
+	id := c.Param("id")
+
+	chunks, err := h.service.FailedChunks(c.Request.Context(), currentWorkspace(c), id)
+	if errors.Is(err, service.ErrDocumentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    chunks,
+	})
+}
+
+// RetryFailed queues a job that embeds the chunks skipped during processing
+func (h *DocumentHandler) RetryFailed(c *gin.Context) {
+	id := c.Param("id")
+
+	doc, err := h.service.RetryFailedChunks(c.Request.Context(), currentWorkspace(c), id)
+	if errors.Is(err, service.ErrDocumentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	if errors.Is(err, service.ErrNoFailedChunks) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to retry chunks of document %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    doc,
+	})
+}
+
+// Reindex rebuilds a document's chunks with new chunking or embedding settings
+func (h *DocumentHandler) Reindex(c *gin.Context) {
+	id := c.Param("id")
+
+	req, ok := bindReindexRequest(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.service.Reindex(c.Request.Context(), currentWorkspace(c), id, *req)
+	switch {
+	case errors.Is(err, service.ErrInvalidIndexSettings):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	case errors.Is(err, service.ErrDocumentBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to reindex document %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    doc,
+	})
+}
+
+// ReindexAll rebuilds the chunks of every document in the workspace that is
+// not being processed
+func (h *DocumentHandler) ReindexAll(c *gin.Context) {
+	req, ok := bindReindexRequest(c)
+	if !ok {
+		return
+	}
+
+	queued, skipped, err := h.service.ReindexAll(c.Request.Context(), currentWorkspace(c), *req)
+	if errors.Is(err, service.ErrInvalidIndexSettings) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to reindex documents: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"queued":  queued,
+		"skipped": skipped,
+	})
+}
+
+// bindReindexRequest reads the optional reindex settings from the body
+func bindReindexRequest(c *gin.Context) (*service.ReindexRequest, bool) {
+	var req service.ReindexRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// progressPollInterval is how often ProgressStream checks for changes
+const progressPollInterval = time.Second
+
+// ProgressStream sends a "progress" event whenever the document's ingestion
+// progress changes and a final "done" event once processing has finished.
+func (h *DocumentHandler) ProgressStream(c *gin.Context) {
+	id := c.Param("id")
+	workspaceID := currentWorkspace(c)
+	ctx := c.Request.Context()
+
+	progress, err := h.service.GetProgress(ctx, workspaceID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	startEventStream(c)
+
+	ticker := time.NewTicker(progressPollInterval)
+	defer ticker.Stop()
+
+	var lastUpdate time.Time
+	for {
+		if !progress.UpdatedAt.Equal(lastUpdate) {
+			if err := sendEvent(c, "progress", progress); err != nil {
+				return
+			}
+			lastUpdate = progress.UpdatedAt
+		}
+
+		if progress.Status != "processing" {
+			sendEvent(c, "done", progress)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		progress, err = h.service.GetProgress(ctx, workspaceID, id)
+		if err != nil {
+			if ctx.Err() == nil {
+				sendEvent(c, "error", gin.H{"error": err.Error()})
+			}
+			return
+		}
+	}
+}
+
 func (h *DocumentHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+	err := h.service.Delete(c.Request.Context(), currentWorkspace(c), id)
+	if errors.Is(err, service.ErrDocumentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -90,7 +376,7 @@ func (h *DocumentHandler) Delete(c *gin.Context) {
 func (h *DocumentHandler) GetFile(c *gin.Context) {
 	id := c.Param("id")
 
-	doc, err := h.service.Get(c.Request.Context(), id)
+	doc, err := h.service.Get(c.Request.Context(), currentWorkspace(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
@@ -115,7 +401,11 @@ func (h *DocumentHandler) GetPageImage(c *gin.Context) {
 	bboxX2 := c.Query("bbox_x2")
 	bboxY2 := c.Query("bbox_y2")
 
-	imageData, err := h.service.RenderPageImage(c.Request.Context(), id, pageNum, bboxX1, bboxY1, bboxX2, bboxY2)
+	imageData, err := h.service.RenderPageImage(c.Request.Context(), currentWorkspace(c), id, pageNum, bboxX1, bboxY1, bboxX2, bboxY2)
+	if errors.Is(err, service.ErrDocumentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type EmbeddingHandler struct {
+	service *service.EmbeddingService
+}
+
+func NewEmbeddingHandler(service *service.EmbeddingService) *EmbeddingHandler {
+	return &EmbeddingHandler{service: service}
+}
+
+// CacheStats returns the embedding cache hit and miss counts
+func (h *EmbeddingHandler) CacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.service.Stats(),
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type EmbeddingSpaceHandler struct {
+	service *service.EmbeddingSpaceService
+}
+
+func NewEmbeddingSpaceHandler(service *service.EmbeddingSpaceService) *EmbeddingSpaceHandler {
+	return &EmbeddingSpaceHandler{service: service}
+}
+
+func (h *EmbeddingSpaceHandler) List(c *gin.Context) {
+	spaces, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"spaces":  spaces,
+	})
+}
+
+func (h *EmbeddingSpaceHandler) Create(c *gin.Context) {
+	var req service.CreateSpaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Dimension < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dimension must be positive"})
+		return
+	}
+
+	space, err := h.service.Create(c.Request.Context(), req)
+	if err != nil {
+		respondSpaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    space,
+	})
+}
+
+// Build queues every indexed document for embedding into the space
+func (h *EmbeddingSpaceHandler) Build(c *gin.Context) {
+	queued, err := h.service.Build(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondSpaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"queued":  queued,
+	})
+}
+
+// Activate switches queries to the space. ?force=true skips the check that
+// every document has been embedded in it.
+func (h *EmbeddingSpaceHandler) Activate(c *gin.Context) {
+	force := c.Query("force") == "true"
+
+	space, err := h.service.Activate(c.Request.Context(), c.Param("id"), force)
+	if err != nil {
+		respondSpaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    space,
+	})
+}
+
+func (h *EmbeddingSpaceHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondSpaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Embedding space deleted",
+	})
+}
+
+func respondSpaceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSpaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Embedding space not found"})
+	case errors.Is(err, service.ErrDimensionMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSpaceExists),
+		errors.Is(err, service.ErrSpaceActive),
+		errors.Is(err, service.ErrSpaceRetired),
+		errors.Is(err, service.ErrSpaceIncomplete):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("ERROR: Embedding space request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type FeedbackHandler struct {
+	service *service.FeedbackService
+}
+
+func NewFeedbackHandler(service *service.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{service: service}
+}
+
+// Submit attaches a rating, comment and per-citation relevance marks to a
+// recorded query result
+func (h *FeedbackHandler) Submit(c *gin.Context) {
+	queryID, ok := parseQueryID(c)
+	if !ok {
+		return
+	}
+
+	var req service.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	feedback, err := h.service.Submit(c.Request.Context(), currentWorkspace(c), queryID, req)
+	switch {
+	case errors.Is(err, service.ErrQueryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Query not found"})
+		return
+	case errors.Is(err, service.ErrInvalidFeedback):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to save feedback on query %d: %v", queryID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// Get returns the feedback on a recorded query
+func (h *FeedbackHandler) Get(c *gin.Context) {
+	queryID, ok := parseQueryID(c)
+	if !ok {
+		return
+	}
+
+	feedback, err := h.service.Get(c.Request.Context(), currentWorkspace(c), queryID)
+	switch {
+	case errors.Is(err, service.ErrQueryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Query not found"})
+		return
+	case errors.Is(err, service.ErrFeedbackNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to get feedback on query %d: %v", queryID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// Export streams the labeled (query, chunk, relevant) examples as JSONL, one
+// object per line. The optional days parameter limits it to recent feedback.
+func (h *FeedbackHandler) Export(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		since = time.Now().AddDate(0, 0, -days)
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="feedback.jsonl"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	err := h.service.Export(c.Request.Context(), currentWorkspace(c), since, func(labeled *domain.LabeledCitation) error {
+		return encoder.Encode(labeled)
+	})
+	if err != nil {
+		// Headers are already sent; the truncated body is all we can return
+		log.Printf("ERROR: Feedback export failed: %v", err)
+	}
+}
+
+// parseQueryID reads the :id parameter of a recorded query, writing a 400
+// response and returning false when it is not a valid ID
+func parseQueryID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query ID"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type SessionHandler struct {
+	service *service.SessionService
+}
+
+func NewSessionHandler(service *service.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+func (h *SessionHandler) List(c *gin.Context) {
+	sessions, err := h.service.List(c.Request.Context(), currentWorkspace(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"sessions": sessions,
+	})
+}
+
+func (h *SessionHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	session, err := h.service.Get(c.Request.Context(), currentWorkspace(c), id)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    session,
+	})
+}
+
+func (h *SessionHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.Delete(c.Request.Context(), currentWorkspace(c), id)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Session deleted",
+	})
+}
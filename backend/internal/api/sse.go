@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startEventStream writes the headers for a Server-Sent Events response
+func startEventStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+}
+
+// sendEvent writes one SSE event and flushes it to the client
+func sendEvent(c *gin.Context, event string, data interface{}) error {
+	if err := c.Request.Context().Err(); err != nil {
+		return err
+	}
+	c.SSEvent(event, data)
+	c.Writer.Flush()
+	return nil
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdf-rag-system/backend/internal/service"
+)
+
+type WorkspaceHandler struct {
+	service *service.WorkspaceService
+}
+
+func NewWorkspaceHandler(service *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{service: service}
+}
+
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	var req service.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	workspace, err := h.service.Create(c.Request.Context(), req)
+	if errors.Is(err, service.ErrInvalidWorkspace) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to create workspace: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    workspace,
+	})
+}
+
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	workspaces, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    workspaces,
+	})
+}
+
+// Get returns a workspace with its quota and usage. Keys bound to a
+// workspace can only see their own.
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	if workspaceID := boundWorkspace(c); workspaceID != nil && *workspaceID != id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	workspace, err := h.service.Get(c.Request.Context(), id)
+	if errors.Is(err, service.ErrWorkspaceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    workspace,
+	})
+}
+
+// Update replaces the name and quotas of a workspace
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req service.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	workspace, err := h.service.Update(c.Request.Context(), id, req)
+	switch {
+	case errors.Is(err, service.ErrInvalidWorkspace):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to update workspace %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    workspace,
+	})
+}
+
+// Delete removes an empty workspace
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.Delete(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrInvalidWorkspace):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	case errors.Is(err, service.ErrWorkspaceNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to delete workspace %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Workspace deleted",
+	})
+}
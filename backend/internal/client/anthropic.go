@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com/v1"
+	anthropicVersion        = "2023-06-01"
+	// anthropicDefaultMaxTokens is used when no max tokens are configured,
+	// since the Messages API requires the field
+	anthropicDefaultMaxTokens = 4096
+)
+
+// AnthropicChat calls the Anthropic Messages API (/messages)
+type AnthropicChat struct {
+	http    *HTTPClient
+	url     string
+	headers map[string]string
+	model   string
+	options ChatOptions
+}
+
+func NewAnthropicChat(httpClient *HTTPClient, baseURL, apiKey, model string, options ChatOptions) *AnthropicChat {
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	return &AnthropicChat{
+		http: httpClient,
+		url:  strings.TrimRight(baseURL, "/") + "/messages",
+		headers: map[string]string{
+			"x-api-key":         apiKey,
+			"anthropic-version": anthropicVersion,
+		},
+		model:   model,
+		options: options,
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	System        string             `json:"system,omitempty"`
+	Messages      []anthropicMessage `json:"messages"`
+	MaxTokens     int                `json:"max_tokens"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage anthropicUsage `json:"usage"`
+}
+
+// anthropicEvent is one server-sent event of a streamed message
+type anthropicEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// request moves system messages into the top-level system prompt, which is
+// where the Messages API takes it
+func (c *AnthropicChat) request(messages []ChatMessage) anthropicRequest {
+	req := anthropicRequest{
+		Model:         c.model,
+		MaxTokens:     c.options.MaxTokens,
+		Temperature:   c.options.Temperature,
+		TopP:          c.options.TopP,
+		StopSequences: c.options.Stop,
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	var system []string
+	for _, message := range messages {
+		if message.Role == "system" {
+			system = append(system, message.Content)
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: message.Role, Content: message.Content})
+	}
+	req.System = strings.Join(system, "\n\n")
+
+	return req
+}
+
+func (u anthropicUsage) tokenUsage() *TokenUsage {
+	return &TokenUsage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.InputTokens + u.OutputTokens,
+	}
+}
+
+func (c *AnthropicChat) Chat(ctx context.Context, messages []ChatMessage) (string, *TokenUsage, error) {
+	var msgResp anthropicResponse
+	if err := c.http.postJSON(ctx, "LLM", c.url, c.headers, c.request(messages), &msgResp); err != nil {
+		return "", nil, err
+	}
+
+	var answer strings.Builder
+	for _, block := range msgResp.Content {
+		if block.Type == "text" {
+			answer.WriteString(block.Text)
+		}
+	}
+	if answer.Len() == 0 {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	return answer.String(), msgResp.Usage.tokenUsage(), nil
+}
+
+// ChatStream streams a message, forwarding the text deltas. Input tokens are
+// reported on message_start and output tokens on message_delta.
+func (c *AnthropicChat) ChatStream(ctx context.Context, messages []ChatMessage, onDelta func(string) error) (string, *TokenUsage, error) {
+	reqBody := c.request(messages)
+	reqBody.Stream = true
+
+	headers := map[string]string{"Accept": "text/event-stream"}
+	for key, value := range c.headers {
+		headers[key] = value
+	}
+
+	resp, err := c.http.stream(ctx, "LLM", c.url, headers, reqBody)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	var usage anthropicUsage
+
+	err = streamLines(resp.Body, func(line string) (bool, error) {
+		data, ok := sseData(line)
+		if !ok {
+			return false, nil
+		}
+
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, fmt.Errorf("invalid stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				return false, nil
+			}
+			answer.WriteString(event.Delta.Text)
+			if err := onDelta(event.Delta.Text); err != nil {
+				return false, err
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			return true, nil
+		case "error":
+			if event.Error != nil {
+				return false, fmt.Errorf("LLM stream error (%s): %s", event.Error.Type, event.Error.Message)
+			}
+			return false, fmt.Errorf("LLM stream error: %s", data)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return answer.String(), nil, err
+	}
+
+	if answer.Len() == 0 {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	return answer.String(), usage.tokenUsage(), nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError is a non-200 response from an LLM or embedding API
+type APIError struct {
+	API        string
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay requested by the server, if any
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.API, e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request may succeed if sent again
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func newAPIError(api string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		API:        api,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+	apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return apiErr
+}
+
+// parseRetryAfter reads a Retry-After header given either as seconds or as
+// an HTTP date. It returns zero when the header is missing, unparsable or
+// already past.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		return 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// IsRetryable reports whether err is a transient failure: a 429 or 5xx
+// response, or a network error other than cancellation.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// NLIClient calls a natural language inference endpoint (/nli) that scores
+// whether each premise entails its hypothesis, such as a local server
+// hosting an MNLI cross-encoder.
+type NLIClient struct {
+	http    *HTTPClient
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func NewNLIClient(httpClient *HTTPClient, baseURL, apiKey, model string) *NLIClient {
+	return &NLIClient{
+		http:    httpClient,
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   model,
+	}
+}
+
+type NLIPair struct {
+	Premise    string `json:"premise"`
+	Hypothesis string `json:"hypothesis"`
+}
+
+type NLIRequest struct {
+	Model string    `json:"model,omitempty"`
+	Pairs []NLIPair `json:"pairs"`
+}
+
+// NLIResult holds the label probabilities of the pair at Index
+type NLIResult struct {
+	Index         int     `json:"index"`
+	Entailment    float64 `json:"entailment"`
+	Neutral       float64 `json:"neutral"`
+	Contradiction float64 `json:"contradiction"`
+}
+
+type NLIResponse struct {
+	Results []NLIResult `json:"results"`
+}
+
+// Infer scores the pairs. Results refer to pairs by index; every pair is
+// expected to have a result.
+func (c *NLIClient) Infer(ctx context.Context, pairs []NLIPair) ([]NLIResult, error) {
+	reqBody := NLIRequest{
+		Model: c.model,
+		Pairs: pairs,
+	}
+
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+
+	var nliResp NLIResponse
+	if err := c.http.postJSON(ctx, "NLI", c.baseURL+"/nli", headers, reqBody, &nliResp); err != nil {
+		return nil, err
+	}
+
+	if len(nliResp.Results) != len(pairs) {
+		return nil, fmt.Errorf("NLI API returned %d results for %d pairs", len(nliResp.Results), len(pairs))
+	}
+	for _, result := range nliResp.Results {
+		if result.Index < 0 || result.Index >= len(pairs) {
+			return nil, fmt.Errorf("NLI result index %d out of range", result.Index)
+		}
+	}
+
+	return nliResp.Results, nil
+}
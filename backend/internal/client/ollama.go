@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const ollamaDefaultBaseURL = "http://localhost:11434"
+
+// OllamaChat calls the native Ollama /api/chat endpoint
+type OllamaChat struct {
+	http    *HTTPClient
+	baseURL string
+	model   string
+	options ChatOptions
+}
+
+func NewOllamaChat(httpClient *HTTPClient, baseURL, model string, options ChatOptions) *OllamaChat {
+	if baseURL == "" {
+		baseURL = ollamaDefaultBaseURL
+	}
+	return &OllamaChat{
+		http:    httpClient,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		options: options,
+	}
+}
+
+// ollamaOptions are the model parameters of an Ollama request
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []ChatMessage  `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  *ollamaOptions `json:"options,omitempty"`
+}
+
+// ollamaChatResponse is the whole response, or one line of a streamed one;
+// the token counts are set once done is true
+type ollamaChatResponse struct {
+	Message         ChatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+	Error           string      `json:"error"`
+}
+
+func (r *ollamaChatResponse) usage() *TokenUsage {
+	return &TokenUsage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+func (c *OllamaChat) request(messages []ChatMessage, stream bool) ollamaChatRequest {
+	req := ollamaChatRequest{
+		Model:    c.model,
+		Messages: messages,
+		Stream:   stream,
+	}
+	options := ollamaOptions{
+		Temperature: c.options.Temperature,
+		TopP:        c.options.TopP,
+		NumPredict:  c.options.MaxTokens,
+		Stop:        c.options.Stop,
+	}
+	if options.Temperature != nil || options.TopP != nil || options.NumPredict > 0 || len(options.Stop) > 0 {
+		req.Options = &options
+	}
+	return req
+}
+
+func (c *OllamaChat) Chat(ctx context.Context, messages []ChatMessage) (string, *TokenUsage, error) {
+	var chatResp ollamaChatResponse
+	if err := c.http.postJSON(ctx, "LLM", c.baseURL+"/api/chat", nil, c.request(messages, false), &chatResp); err != nil {
+		return "", nil, err
+	}
+
+	if chatResp.Error != "" {
+		return "", nil, fmt.Errorf("LLM error: %s", chatResp.Error)
+	}
+	if chatResp.Message.Content == "" {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	return chatResp.Message.Content, chatResp.usage(), nil
+}
+
+// ChatStream reads the newline-delimited JSON objects Ollama streams until
+// one is marked done
+func (c *OllamaChat) ChatStream(ctx context.Context, messages []ChatMessage, onDelta func(string) error) (string, *TokenUsage, error) {
+	resp, err := c.http.stream(ctx, "LLM", c.baseURL+"/api/chat", nil, c.request(messages, true))
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	var usage *TokenUsage
+
+	err = streamLines(resp.Body, func(line string) (bool, error) {
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, fmt.Errorf("invalid stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return false, fmt.Errorf("LLM stream error: %s", chunk.Error)
+		}
+
+		if chunk.Message.Content != "" {
+			answer.WriteString(chunk.Message.Content)
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return false, err
+			}
+		}
+
+		if chunk.Done {
+			usage = chunk.usage()
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return answer.String(), nil, err
+	}
+
+	if answer.Len() == 0 {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	return answer.String(), usage, nil
+}
+
+// OllamaEmbedder calls the native Ollama /api/embed endpoint
+type OllamaEmbedder struct {
+	http    *HTTPClient
+	baseURL string
+}
+
+func NewOllamaEmbedder(httpClient *HTTPClient, baseURL string) *OllamaEmbedder {
+	if baseURL == "" {
+		baseURL = ollamaDefaultBaseURL
+	}
+	return &OllamaEmbedder{http: httpClient, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// Embed embeds several texts in one request; Ollama returns the embeddings
+// in input order
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string, model string) ([][]float64, error) {
+	reqBody := ollamaEmbedRequest{
+		Model: model,
+		Input: texts,
+	}
+
+	var embResp ollamaEmbedResponse
+	if err := e.http.postJSON(ctx, "Embedding", e.baseURL+"/api/embed", nil, reqBody, &embResp); err != nil {
+		return nil, err
+	}
+
+	if len(embResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embResp.Embeddings))
+	}
+	for i, embedding := range embResp.Embeddings {
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+
+	return embResp.Embeddings, nil
+}
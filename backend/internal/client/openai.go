@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const openAIDefaultBaseURL = "https://api.openai.com/v1"
+
+// OpenAIChat calls an OpenAI-compatible /chat/completions endpoint. It also
+// serves Azure OpenAI, which speaks the same wire format on a per-deployment
+// URL with a different auth header.
+type OpenAIChat struct {
+	http    *HTTPClient
+	url     string
+	headers map[string]string
+	model   string
+	options ChatOptions
+}
+
+func NewOpenAIChat(httpClient *HTTPClient, baseURL, apiKey, model string, options ChatOptions) *OpenAIChat {
+	if baseURL == "" {
+		baseURL = openAIDefaultBaseURL
+	}
+	return &OpenAIChat{
+		http:    httpClient,
+		url:     strings.TrimRight(baseURL, "/") + "/chat/completions",
+		headers: map[string]string{"Authorization": "Bearer " + apiKey},
+		model:   model,
+		options: options,
+	}
+}
+
+// NewAzureChat calls the chat completions of an Azure OpenAI deployment.
+// endpoint is the resource endpoint (https://<resource>.openai.azure.com).
+func NewAzureChat(httpClient *HTTPClient, endpoint, apiKey, deployment, apiVersion string, options ChatOptions) *OpenAIChat {
+	return &OpenAIChat{
+		http:    httpClient,
+		url:     azureURL(endpoint, deployment, "chat/completions", apiVersion),
+		headers: map[string]string{"api-key": apiKey},
+		model:   deployment,
+		options: options,
+	}
+}
+
+func azureURL(endpoint, deployment, operation, apiVersion string) string {
+	return fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s",
+		strings.TrimRight(endpoint, "/"), url.PathEscape(deployment), operation, url.QueryEscape(apiVersion))
+}
+
+type ChatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []ChatMessage  `json:"messages"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	TopP          *float64       `json:"top_p,omitempty"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Stop          []string       `json:"stop,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions asks for a final chunk carrying the token usage
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type ChatResponse struct {
+	Choices []struct {
+		Message ChatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *TokenUsage `json:"usage"`
+}
+
+func (c *OpenAIChat) request(messages []ChatMessage) ChatRequest {
+	return ChatRequest{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: c.options.Temperature,
+		TopP:        c.options.TopP,
+		MaxTokens:   c.options.MaxTokens,
+		Stop:        c.options.Stop,
+	}
+}
+
+func (c *OpenAIChat) Chat(ctx context.Context, messages []ChatMessage) (string, *TokenUsage, error) {
+	var chatResp ChatResponse
+	if err := c.http.postJSON(ctx, "LLM", c.url, c.headers, c.request(messages), &chatResp); err != nil {
+		return "", nil, err
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
+}
+
+type ChatStreamChunk struct {
+	Choices []struct {
+		Delta        ChatMessage `json:"delta"`
+		FinishReason *string     `json:"finish_reason"`
+	} `json:"choices"`
+	// Usage is only set on the final chunk when include_usage was requested
+	Usage *TokenUsage `json:"usage"`
+}
+
+// ChatStream calls /chat/completions with stream: true, reading the
+// server-sent events until [DONE]
+func (c *OpenAIChat) ChatStream(ctx context.Context, messages []ChatMessage, onDelta func(string) error) (string, *TokenUsage, error) {
+	reqBody := c.request(messages)
+	reqBody.Stream = true
+	reqBody.StreamOptions = &StreamOptions{IncludeUsage: true}
+
+	headers := map[string]string{"Accept": "text/event-stream"}
+	for key, value := range c.headers {
+		headers[key] = value
+	}
+
+	resp, err := c.http.stream(ctx, "LLM", c.url, headers, reqBody)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	var usage *TokenUsage
+
+	err = streamLines(resp.Body, func(line string) (bool, error) {
+		data, ok := sseData(line)
+		if !ok {
+			return false, nil
+		}
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var chunk ChatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("invalid stream chunk: %w", err)
+		}
+
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			answer.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return answer.String(), nil, err
+	}
+
+	if answer.Len() == 0 {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	return answer.String(), usage, nil
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint, or the
+// embeddings of Azure OpenAI deployments
+type OpenAIEmbedder struct {
+	http    *HTTPClient
+	url     func(model string) string
+	headers map[string]string
+}
+
+func NewOpenAIEmbedder(httpClient *HTTPClient, baseURL, apiKey string) *OpenAIEmbedder {
+	if baseURL == "" {
+		baseURL = openAIDefaultBaseURL
+	}
+	endpoint := strings.TrimRight(baseURL, "/") + "/embeddings"
+	return &OpenAIEmbedder{
+		http:    httpClient,
+		url:     func(string) string { return endpoint },
+		headers: map[string]string{"Authorization": "Bearer " + apiKey},
+	}
+}
+
+// NewAzureEmbedder embeds with Azure OpenAI; the model passed to Embed is
+// the deployment name
+func NewAzureEmbedder(httpClient *HTTPClient, endpoint, apiKey, apiVersion string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		http: httpClient,
+		url: func(deployment string) string {
+			return azureURL(endpoint, deployment, "embeddings", apiVersion)
+		},
+		headers: map[string]string{"api-key": apiKey},
+	}
+}
+
+type BatchEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type BatchEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed embeds several texts in one request using the array form of the
+// /embeddings input
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string, model string) ([][]float64, error) {
+	reqBody := BatchEmbeddingRequest{
+		Model: model,
+		Input: texts,
+	}
+
+	var embResp BatchEmbeddingResponse
+	if err := e.http.postJSON(ctx, "Embedding", e.url(model), e.headers, reqBody, &embResp); err != nil {
+		return nil, err
+	}
+
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embResp.Data))
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for _, item := range embResp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+
+	return embeddings, nil
+}
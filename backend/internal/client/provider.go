@@ -0,0 +1,150 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Providers selectable for chat and embeddings
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAzure     = "azure"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// ChatProvider generates chat completions. Messages use the system, user and
+// assistant roles; providers without a system role move it where their API
+// expects it. Usage is nil if the API did not report it.
+type ChatProvider interface {
+	Chat(ctx context.Context, messages []ChatMessage) (string, *TokenUsage, error)
+	// ChatStream invokes onDelta for every piece of the answer as it arrives
+	// and returns the full answer once the stream ends. Cancelling ctx aborts
+	// the upstream request.
+	ChatStream(ctx context.Context, messages []ChatMessage, onDelta func(string) error) (string, *TokenUsage, error)
+}
+
+// Embedder embeds texts with a model. Embeddings are returned in the order
+// of texts. Non-200 responses are returned as *APIError.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string, model string) ([][]float64, error)
+}
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// TokenUsage is the token accounting reported by a chat API
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add accumulates other into u
+func (u *TokenUsage) Add(other *TokenUsage) {
+	if other == nil {
+		return
+	}
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// ChatOptions are the generation settings sent with every chat request.
+// Zero values leave the provider's default in place.
+type ChatOptions struct {
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+	Stop        []string
+}
+
+// ProviderConfig selects and configures a chat provider or embedder
+type ProviderConfig struct {
+	// Provider is openai, azure, anthropic or ollama
+	Provider string
+	// BaseURL is the API root; for azure it is the resource endpoint
+	BaseURL string
+	APIKey  string
+	// Model is the model name; for azure it is the deployment name
+	Model string
+	// APIVersion is the api-version of azure requests
+	APIVersion string
+	Options    ChatOptions
+	HTTP       *HTTPClient
+}
+
+// NewChatProvider builds the chat provider selected by cfg.Provider
+func NewChatProvider(cfg ProviderConfig) (ChatProvider, error) {
+	switch cfg.Provider {
+	case "", ProviderOpenAI:
+		return NewOpenAIChat(cfg.HTTP, cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Options), nil
+	case ProviderAzure:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("the %s chat provider needs the resource endpoint as base URL", ProviderAzure)
+		}
+		return NewAzureChat(cfg.HTTP, cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.APIVersion, cfg.Options), nil
+	case ProviderAnthropic:
+		return NewAnthropicChat(cfg.HTTP, cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Options), nil
+	case ProviderOllama:
+		return NewOllamaChat(cfg.HTTP, cfg.BaseURL, cfg.Model, cfg.Options), nil
+	default:
+		return nil, fmt.Errorf("unknown chat provider %q", cfg.Provider)
+	}
+}
+
+// NewEmbedder builds the embedder selected by cfg.Provider. Anthropic has no
+// embeddings API.
+func NewEmbedder(cfg ProviderConfig) (Embedder, error) {
+	switch cfg.Provider {
+	case "", ProviderOpenAI:
+		return NewOpenAIEmbedder(cfg.HTTP, cfg.BaseURL, cfg.APIKey), nil
+	case ProviderAzure:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("the %s embedder needs the resource endpoint as base URL", ProviderAzure)
+		}
+		return NewAzureEmbedder(cfg.HTTP, cfg.BaseURL, cfg.APIKey, cfg.APIVersion), nil
+	case ProviderOllama:
+		return NewOllamaEmbedder(cfg.HTTP, cfg.BaseURL), nil
+	case ProviderAnthropic:
+		return nil, fmt.Errorf("the %s provider has no embeddings API", ProviderAnthropic)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.Provider)
+	}
+}
+
+// streamLines calls onLine with every non-empty line of an SSE or NDJSON
+// body until it returns done or an error
+func streamLines(body io.Reader, onLine func(line string) (done bool, err error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		done, err := onLine(line)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// sseData returns the payload of an SSE data line; ok is false for blank
+// separators, comments and event/id fields
+func sseData(line string) (data string, ok bool) {
+	if !strings.HasPrefix(line, "data:") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "data:")), true
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// RerankClient calls a Cohere/Jina-style /rerank endpoint, such as a local
+// cross-encoder server.
+type RerankClient struct {
+	http    *HTTPClient
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func NewRerankClient(httpClient *HTTPClient, baseURL, apiKey, model string) *RerankClient {
+	return &RerankClient{
+		http:    httpClient,
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   model,
+	}
+}
+
+type RerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type RerankResult struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+type RerankResponse struct {
+	Results []RerankResult `json:"results"`
+}
+
+// Rerank scores documents against query. Results refer to documents by index
+// and are ordered by descending relevance.
+func (c *RerankClient) Rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error) {
+	reqBody := RerankRequest{
+		Model:     c.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      topN,
+	}
+
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+
+	var rerankResp RerankResponse
+	if err := c.http.postJSON(ctx, "Rerank", c.baseURL+"/rerank", headers, reqBody, &rerankResp); err != nil {
+		return nil, err
+	}
+
+	for _, result := range rerankResp.Results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return nil, fmt.Errorf("rerank result index %d out of range", result.Index)
+		}
+	}
+
+	return rerankResp.Results, nil
+}
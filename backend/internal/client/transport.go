@@ -0,0 +1,310 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending a request while an endpoint's
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// HTTPOptions tune the HTTP layer shared by the LLM, embedding, rerank and
+// NLI clients
+type HTTPOptions struct {
+	// Timeout bounds one attempt of a regular call, including reading the body
+	Timeout time.Duration
+	// StreamTimeout bounds one attempt of a streamed call, including the stream
+	StreamTimeout time.Duration
+	// MaxRetries is how often a 429, 5xx, timeout or network error is retried
+	MaxRetries int
+	// RetryBackoff is the base delay, doubled per attempt up to RetryBackoffMax;
+	// the actual delay is drawn at random below it (full jitter)
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+	// BreakerFailures consecutive failures of an endpoint open its circuit
+	// for BreakerCooldown; zero disables the breaker
+	BreakerFailures int
+	BreakerCooldown time.Duration
+	// MaxIdleConnsPerHost is how many keep-alive connections are pooled per host
+	MaxIdleConnsPerHost int
+}
+
+// HTTPClient sends JSON requests over a shared, tuned transport with
+// per-attempt timeouts, retries with jittered exponential backoff and a
+// circuit breaker per endpoint host
+type HTTPClient struct {
+	client   *http.Client
+	options  HTTPOptions
+	breakers *breakerSet
+}
+
+func NewHTTPClient(options HTTPOptions) *HTTPClient {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   options.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &HTTPClient{
+		// Timeouts are applied per attempt through the request context, so
+		// streams are not cut off by a client-wide deadline
+		client:   &http.Client{Transport: transport},
+		options:  options,
+		breakers: &breakerSet{breakers: make(map[string]*breaker)},
+	}
+}
+
+// WithRetry returns a client sharing the transport and circuit breakers but
+// retrying with its own policy
+func (c *HTTPClient) WithRetry(maxRetries int, backoff time.Duration) *HTTPClient {
+	options := c.options
+	options.MaxRetries = maxRetries
+	options.RetryBackoff = backoff
+	return &HTTPClient{client: c.client, options: options, breakers: c.breakers}
+}
+
+// postJSON sends body as JSON to url and decodes a 200 response into out.
+// Other statuses are returned as *APIError named after api.
+func (c *HTTPClient) postJSON(ctx context.Context, api, url string, headers map[string]string, body, out interface{}) error {
+	resp, err := c.send(ctx, api, url, headers, body, c.options.Timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// stream sends body as JSON to url and returns the response for the caller
+// to read as a stream and close. Only failures before the response arrives
+// are retried, since the caller may already have consumed part of a stream.
+func (c *HTTPClient) stream(ctx context.Context, api, url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	return c.send(ctx, api, url, headers, body, c.options.StreamTimeout)
+}
+
+func (c *HTTPClient) send(ctx context.Context, api, rawURL string, headers map[string]string, body interface{}, timeout time.Duration) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker := c.breakers.get(api, rawURL, c.options)
+
+	for attempt := 0; ; attempt++ {
+		if wait, ok := breaker.allow(); !ok {
+			return nil, fmt.Errorf("%s API: %w (retry in %v)", api, ErrCircuitOpen, wait.Round(time.Second))
+		}
+
+		resp, err := c.attempt(ctx, api, rawURL, headers, jsonData, timeout)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled by the caller; says nothing about the endpoint
+			breaker.release()
+			return nil, err
+		}
+		// A timed-out attempt is retried as long as the caller's context is live
+		retryable := err != nil && (IsRetryable(err) || errors.Is(err, context.DeadlineExceeded))
+		breaker.record(retryable)
+
+		if err == nil || !retryable || attempt >= c.options.MaxRetries {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			// Waiting longer than the backoff cap would stall the request or
+			// ingestion worker, so give up and let the caller decide
+			if max := c.options.RetryBackoffMax; max > 0 && apiErr.RetryAfter > max {
+				return nil, fmt.Errorf("%s API asked to retry after %v, longer than the %v limit: %w",
+					api, apiErr.RetryAfter.Round(time.Second), max, err)
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		fmt.Printf("WARNING: %s API request failed (attempt %d/%d), retrying in %v: %v\n",
+			api, attempt+1, c.options.MaxRetries+1, delay.Round(time.Millisecond), err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends one request under its own timeout. On success the timeout
+// stays in force until the caller closes the body.
+func (c *HTTPClient) attempt(ctx context.Context, api, url string, headers map[string]string, body []byte, timeout time.Duration) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(api, resp, data)
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff is the delay before retry attempt+1: a random duration up to
+// RetryBackoff * 2^attempt, capped at RetryBackoffMax
+func (c *HTTPClient) backoff(attempt int) time.Duration {
+	ceiling := c.options.RetryBackoff << attempt
+	if max := c.options.RetryBackoffMax; max > 0 && (ceiling > max || ceiling <= 0) {
+		ceiling = max
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// breakerSet holds one circuit breaker per API and host
+type breakerSet struct {
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func (s *breakerSet) get(api, rawURL string, options HTTPOptions) *breaker {
+	key := api
+	if u, err := url.Parse(rawURL); err == nil {
+		key += " " + u.Host
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[key]
+	if !ok {
+		b = &breaker{name: key, threshold: options.BreakerFailures, cooldown: options.BreakerCooldown}
+		s.breakers[key] = b
+	}
+	return b
+}
+
+// breaker opens after threshold consecutive failures. Once the cooldown has
+// passed it lets a single trial request through (half-open): success closes
+// it, failure opens it for another cooldown.
+type breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// allow reports whether a request may be sent, or how long the circuit
+// stays open
+func (b *breaker) allow() (time.Duration, bool) {
+	if b.threshold <= 0 {
+		return 0, true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return 0, true
+	}
+	if wait := time.Until(b.openUntil); wait > 0 {
+		return wait, false
+	}
+	if b.trial {
+		// Another request is already probing the endpoint
+		return b.cooldown, false
+	}
+	b.trial = true
+	return 0, true
+}
+
+// release ends a trial request without counting its outcome
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// record counts the outcome of a request; only transient failures count,
+// since a 4xx means the endpoint is up
+func (b *breaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !failed {
+		if b.failures >= b.threshold {
+			fmt.Printf("Circuit breaker for %s closed\n", b.name)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		fmt.Printf("WARNING: Circuit breaker for %s open for %v after %d consecutive failures\n", b.name, b.cooldown, b.failures)
+	}
+}
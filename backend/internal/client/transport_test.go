@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient(options HTTPOptions) *HTTPClient {
+	if options.Timeout == 0 {
+		options.Timeout = 5 * time.Second
+	}
+	return NewHTTPClient(options)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"missing", "", 0},
+		{"seconds", "5", 5 * time.Second},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"garbage", "soon", 0},
+		{"date in the past", "Wed, 21 Oct 2015 07:28:00 GMT", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("date in the future", func(t *testing.T) {
+		value := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+		// HTTP dates have a resolution of one second
+		if got := parseRetryAfter(value); got < 88*time.Second || got > 90*time.Second {
+			t.Errorf("parseRetryAfter(%q) = %v, want about 90s", value, got)
+		}
+	})
+}
+
+func TestSendHonorsRetryAfter(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	c := testClient(HTTPOptions{MaxRetries: 2, RetryBackoff: time.Millisecond, RetryBackoffMax: 5 * time.Second})
+
+	start := time.Now()
+	var out map[string]bool
+	if err := c.postJSON(context.Background(), "Test", server.URL, nil, map[string]string{}, &out); err != nil {
+		t.Fatalf("postJSON failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the requested 1s", elapsed)
+	}
+	if hits != 2 || !out["ok"] {
+		t.Errorf("hits = %d, out = %v; want 2 hits and the second response", hits, out)
+	}
+}
+
+func TestSendGivesUpOnLongRetryAfter(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := testClient(HTTPOptions{MaxRetries: 3, RetryBackoff: time.Millisecond, RetryBackoffMax: time.Second})
+
+	start := time.Now()
+	err := c.postJSON(context.Background(), "Test", server.URL, nil, map[string]string{}, &struct{}{})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Hour {
+		t.Fatalf("err = %v, want the API error asking for 1h", err)
+	}
+	if hits != 1 {
+		t.Errorf("hits = %d, want 1 (no retries)", hits)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %v, want immediately", elapsed)
+	}
+}
+
+func TestBreakerOpensAndAllowsOneTrial(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cooldown := 50 * time.Millisecond
+	c := testClient(HTTPOptions{BreakerFailures: 2, BreakerCooldown: cooldown})
+	call := func() error {
+		return c.postJSON(context.Background(), "Test", server.URL, nil, map[string]string{}, &struct{}{})
+	}
+
+	for i := 0; i < 2; i++ {
+		var apiErr *APIError
+		if err := call(); !errors.As(err, &apiErr) {
+			t.Fatalf("call %d: err = %v, want an API error", i+1, err)
+		}
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want the circuit open after 2 failures", err)
+	}
+	if hits != 2 {
+		t.Errorf("hits = %d, want 2; an open circuit sends nothing", hits)
+	}
+
+	time.Sleep(cooldown + 10*time.Millisecond)
+
+	b := c.breakers.get("Test", server.URL, c.options)
+	if _, ok := b.allow(); !ok {
+		t.Fatal("breaker refused the trial request after the cooldown")
+	}
+	if _, ok := b.allow(); ok {
+		t.Error("breaker allowed a second request while the trial is in flight")
+	}
+	b.record(true)
+	if _, ok := b.allow(); ok {
+		t.Error("breaker allowed a request right after the trial failed")
+	}
+}
+
+func TestBreakerReleasesCancelledTrial(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Hang until the test is done; the client gives up first
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+
+	cooldown := 20 * time.Millisecond
+	c := testClient(HTTPOptions{BreakerFailures: 1, BreakerCooldown: cooldown})
+	c.postJSON(context.Background(), "Test", server.URL, nil, map[string]string{}, &struct{}{})
+
+	time.Sleep(cooldown + 10*time.Millisecond)
+	fail.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.postJSON(ctx, "Test", server.URL, nil, map[string]string{}, &struct{}{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the caller's deadline", err)
+	}
+
+	// The cancelled trial says nothing about the endpoint, so the next
+	// request may probe it again
+	b := c.breakers.get("Test", server.URL, c.options)
+	if _, ok := b.allow(); !ok {
+		t.Error("breaker still holds the trial of a cancelled request")
+	}
+}
+
+func TestBackoffStaysWithinCap(t *testing.T) {
+	c := testClient(HTTPOptions{RetryBackoff: 500 * time.Millisecond, RetryBackoffMax: 30 * time.Second})
+
+	for attempt := 0; attempt < 200; attempt++ {
+		ceiling := c.options.RetryBackoffMax
+		if attempt < 6 {
+			ceiling = c.options.RetryBackoff << attempt
+		}
+		for i := 0; i < 20; i++ {
+			if delay := c.backoff(attempt); delay <= 0 || delay > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within (0, %v]", attempt, delay, ceiling)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// API key scopes, from least to most privileged. A key may do everything
+// the scopes below its own allow.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var scopeLevels = map[string]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// ValidScope reports whether scope is read, write or admin
+func ValidScope(scope string) bool {
+	_, ok := scopeLevels[scope]
+	return ok
+}
+
+// APIKey is a key for calling the API. Only the SHA-256 of the key is
+// stored; the key itself is shown once, when it is created.
+type APIKey struct {
+	ID   string `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Name string `json:"name" gorm:"type:varchar(255);not null"`
+	// Prefix is the start of the key, to tell keys apart in listings
+	Prefix  string `json:"prefix" gorm:"type:varchar(16);not null"`
+	KeyHash string `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scope   string `json:"scope" gorm:"type:varchar(10);not null"`
+	// WorkspaceID binds the key to one workspace; nil keys may act in any
+	// workspace, selected per request
+	WorkspaceID *string `json:"workspace_id" gorm:"type:varchar(36);index"`
+	// QueryRateLimit and UploadRateLimit override the configured requests
+	// per minute for this key
+	QueryRateLimit  *int       `json:"query_rate_limit,omitempty"`
+	UploadRateLimit *int       `json:"upload_rate_limit,omitempty"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Allows reports whether the key's scope covers scope
+func (k *APIKey) Allows(scope string) bool {
+	return scopeLevels[k.Scope] >= scopeLevels[scope] && scopeLevels[scope] > 0
+}
+
+// Revoked reports whether the key has been revoked
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
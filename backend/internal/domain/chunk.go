@@ -19,10 +19,18 @@ type Chunk struct {
 	BboxY1     *float64        `json:"bbox_y1,omitempty" gorm:"type:float"`
 	BboxX2     *float64        `json:"bbox_x2,omitempty" gorm:"type:float"`
 	BboxY2     *float64        `json:"bbox_y2,omitempty" gorm:"type:float"`
-	Embedding  pgvector.Vector `json:"-" gorm:"type:vector(1536)"`
+	Embedding  pgvector.Vector `json:"-" gorm:"type:vector"`
+	ContentTSV string          `json:"-" gorm:"->:false;type:tsvector GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;index:idx_chunks_content_tsv,type:gin"`
 	CreatedAt  time.Time       `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt  time.Time       `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
 
+	// EmbeddingSpaceID is the space, and so the model, the embedding belongs to
+	EmbeddingSpaceID string `json:"embedding_space_id" gorm:"type:varchar(36);index"`
+
+	// WorkspaceID is the workspace of the document, repeated on each chunk
+	// so searches are scoped without trusting the requested document IDs
+	WorkspaceID string `json:"workspace_id" gorm:"type:varchar(36);not null;index"`
+
 	// Relations
 	Document Document `json:"document,omitempty" gorm:"foreignKey:DocumentID"`
 }
@@ -57,4 +65,13 @@ type SearchResult struct {
 	Chunk
 	Score    float64 `json:"score"`
 	Filename string  `json:"filename"`
+	// VectorScore is the cosine similarity, KeywordScore the full-text rank.
+	// Each is zero when the chunk was not found by that search.
+	VectorScore  float64 `json:"vector_score" gorm:"-"`
+	KeywordScore float64 `json:"keyword_score" gorm:"-"`
+	// IdentifierMatch is set when the keyword search matched an
+	// identifier-like term of the query (part numbers, clause IDs, paths)
+	IdentifierMatch bool `json:"identifier_match"`
+	// RerankScore is the relevance assigned by the reranking stage
+	RerankScore float64 `json:"rerank_score" gorm:"-"`
 }
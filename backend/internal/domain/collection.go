@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Collection is a named group of documents in a workspace. A document may
+// belong to any number of collections.
+type Collection struct {
+	ID          string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	WorkspaceID string    `json:"workspace_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_collections_workspace_name"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null;uniqueIndex:idx_collections_workspace_name"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	// DocumentCount is filled in by listings
+	DocumentCount int64 `json:"document_count" gorm:"->;-:migration"`
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionDocument is the membership of a document in a collection
+type CollectionDocument struct {
+	CollectionID string    `json:"collection_id" gorm:"type:varchar(36);primaryKey"`
+	DocumentID   string    `json:"document_id" gorm:"type:varchar(36);primaryKey;index"`
+	AddedAt      time.Time `json:"added_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (CollectionDocument) TableName() string {
+	return "collection_documents"
+}
@@ -1,20 +1,214 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Document statuses
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	// StatusPartial means some chunks could not be embedded and were skipped
+	StatusPartial = "partial"
+	StatusError   = "error"
+)
+
+// Error codes recorded when processing fails
+const (
+	ErrCodeFileMissing          = "file_missing"
+	ErrCodeDocreaderUnavailable = "docreader_unavailable"
+	ErrCodeParseFailed          = "parse_failed"
+	ErrCodeNoContent            = "no_content"
+	ErrCodeEmbeddingFailed      = "embedding_failed"
+	ErrCodeStorageFailed        = "storage_failed"
+	ErrCodeTimeout              = "timeout"
+	ErrCodeInternal             = "internal"
+)
+
+// Processing stages reported while a document is ingested
+const (
+	StageQueued    = "queued"
+	StageParsing   = "parsing"
+	StageEmbedding = "embedding"
+	StageStoring   = "storing"
+	StageDone      = "done"
+)
 
 // Document represents a PDF document
 type Document struct {
 	ID          string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	WorkspaceID string    `json:"workspace_id" gorm:"type:varchar(36);not null;index"`
 	Filename    string    `json:"filename" gorm:"type:varchar(255);not null"`
 	FilePath    string    `json:"file_path" gorm:"type:varchar(512);not null"`
 	FileSize    int64     `json:"file_size" gorm:"not null"`
 	TotalPages  int       `json:"total_pages" gorm:"default:0"`
 	UploadTime  time.Time `json:"upload_time" gorm:"not null;default:CURRENT_TIMESTAMP"`
 	Status      string    `json:"status" gorm:"type:varchar(50);default:'processing'"`
-	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	// Title, Author and CreationDate are read from the PDF info dictionary
+	// at upload and can be edited afterwards
+	Title        string     `json:"title" gorm:"type:varchar(512);not null;default:''"`
+	Author       string     `json:"author" gorm:"type:varchar(255);not null;default:''"`
+	CreationDate *time.Time `json:"creation_date,omitempty"`
+	// Tags and Metadata are assigned by users and can filter retrieval
+	Tags     StringList `json:"tags" gorm:"type:jsonb;not null;default:'[]'"`
+	Metadata Metadata   `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+
+	// SHA-256 of the file, used to detect duplicate uploads
+	ContentHash string `json:"content_hash" gorm:"type:varchar(64);index"`
+	// AliasOf is set on a duplicate upload that reuses another document's chunks
+	AliasOf *string `json:"alias_of,omitempty" gorm:"type:varchar(36);index"`
+
+	// Ingestion progress
+	ProcessingStage string     `json:"processing_stage" gorm:"type:varchar(20)"`
+	ChunksDone      int        `json:"chunks_done" gorm:"default:0"`
+	ChunksTotal     int        `json:"chunks_total" gorm:"default:0"`
+	StageStartedAt  *time.Time `json:"stage_started_at,omitempty"`
+	ETA             *time.Time `json:"eta,omitempty"`
+	LastError       string     `json:"last_error,omitempty" gorm:"type:text"`
+
+	// Settings the current chunks were built with
+	IndexSettings `gorm:"embedded"`
+
+	// Failure details
+	ErrorCode     string `json:"error_code,omitempty" gorm:"type:varchar(50)"`
+	ErrorMessage  string `json:"error_message,omitempty" gorm:"type:text"`
+	SkippedChunks int    `json:"skipped_chunks" gorm:"default:0"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
 }
 
 func (Document) TableName() string {
 	return "documents"
 }
+
+// ChunkSourceID returns the ID of the document whose chunks this document uses
+func (d *Document) ChunkSourceID() string {
+	if d.AliasOf != nil {
+		return *d.AliasOf
+	}
+	return d.ID
+}
+
+// DocumentFilter restricts retrieval to documents matching all of its
+// conditions. Dates are YYYY-MM-DD and bound the creation date inclusively.
+type DocumentFilter struct {
+	// Tags matches documents with any of the tags
+	Tags []string `json:"tags"`
+	// Author matches case-insensitively
+	Author        string `json:"author"`
+	CreatedAfter  string `json:"created_after"`
+	CreatedBefore string `json:"created_before"`
+	// Metadata matches documents with all of the key/value pairs
+	Metadata map[string]string `json:"metadata"`
+}
+
+// Empty reports whether the filter has no conditions
+func (f *DocumentFilter) Empty() bool {
+	return f == nil || (len(f.Tags) == 0 && f.Author == "" && f.CreatedAfter == "" && f.CreatedBefore == "" && len(f.Metadata) == 0)
+}
+
+// Validate checks the dates of the filter
+func (f *DocumentFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, date := range []string{f.CreatedAfter, f.CreatedBefore} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, date); err != nil {
+			return fmt.Errorf("invalid date %q: dates must be YYYY-MM-DD", date)
+		}
+	}
+	return nil
+}
+
+// NormalizeTags trims and lowercases tags, dropping empty and repeated ones
+func NormalizeTags(tags []string) StringList {
+	seen := make(map[string]bool, len(tags))
+	normalized := make(StringList, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// DateLayout is the format of dates in filters and document edits
+const DateLayout = "2006-01-02"
+
+// Fields documents can be listed by
+const (
+	DocumentSortUploadTime = "upload_time"
+	DocumentSortFilename   = "filename"
+	DocumentSortFileSize   = "file_size"
+)
+
+// IndexSettings control how a document is split into chunks and embedded
+type IndexSettings struct {
+	ChunkSize      int    `json:"chunk_size" gorm:"default:0"`
+	ChunkOverlap   int    `json:"chunk_overlap" gorm:"default:0"`
+	EmbeddingModel string `json:"embedding_model" gorm:"type:varchar(100)"`
+}
+
+// DocumentProgress is a snapshot of a document's ingestion progress
+type DocumentProgress struct {
+	DocumentID  string     `json:"document_id"`
+	Status      string     `json:"status"`
+	Stage       string     `json:"stage"`
+	ChunksDone  int        `json:"chunks_done"`
+	ChunksTotal int        `json:"chunks_total"`
+	Percent     float64    `json:"percent"`
+	ETA         *time.Time `json:"eta,omitempty"`
+	ETASeconds  *int       `json:"eta_seconds,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	ErrorCode   string     `json:"error_code,omitempty"`
+	Skipped     int        `json:"skipped_chunks"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Progress returns the document's current ingestion progress
+func (d *Document) Progress() *DocumentProgress {
+	progress := &DocumentProgress{
+		DocumentID:  d.ID,
+		Status:      d.Status,
+		Stage:       d.ProcessingStage,
+		ChunksDone:  d.ChunksDone,
+		ChunksTotal: d.ChunksTotal,
+		LastError:   d.LastError,
+		ErrorCode:   d.ErrorCode,
+		Skipped:     d.SkippedChunks,
+		UpdatedAt:   d.UpdatedAt,
+	}
+
+	if d.ChunksTotal > 0 {
+		progress.Percent = float64(d.ChunksDone) * 100 / float64(d.ChunksTotal)
+	}
+	switch d.Status {
+	case StatusCompleted, StatusPartial:
+		progress.Percent = 100
+		return progress
+	case StatusError:
+		// A failed document shows how far it got before the error
+		return progress
+	}
+
+	if d.ETA != nil {
+		progress.ETA = d.ETA
+		seconds := int(time.Until(*d.ETA).Seconds())
+		if seconds < 0 {
+			seconds = 0
+		}
+		progress.ETASeconds = &seconds
+	}
+
+	return progress
+}
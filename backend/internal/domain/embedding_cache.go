@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// CachedEmbedding is a previously computed embedding of a text, keyed by the
+// embedding model and the SHA-256 of the normalized text
+type CachedEmbedding struct {
+	Model     string          `json:"model" gorm:"type:varchar(100);primaryKey"`
+	TextHash  string          `json:"text_hash" gorm:"type:varchar(64);primaryKey"`
+	Embedding pgvector.Vector `json:"-" gorm:"type:vector;not null"`
+	CreatedAt time.Time       `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (CachedEmbedding) TableName() string {
+	return "embedding_cache"
+}
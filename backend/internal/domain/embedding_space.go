@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Embedding space states
+const (
+	// SpaceStatusActive is the space queries are answered from; there is one
+	SpaceStatusActive = "active"
+	// SpaceStatusBuilding is a space being filled alongside the active one
+	SpaceStatusBuilding = "building"
+	// SpaceStatusRetired is a former active space that is no longer updated
+	SpaceStatusRetired = "retired"
+)
+
+// EmbeddingSpace is a set of chunk embeddings produced by one embedding model.
+// Several spaces can exist side by side so a new model can be filled in
+// before queries are switched over to it.
+type EmbeddingSpace struct {
+	ID        string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Model     string    `json:"model" gorm:"type:varchar(100);not null"`
+	Version   string    `json:"version" gorm:"type:varchar(50)"`
+	Dimension int       `json:"dimension" gorm:"not null"`
+	Status    string    `json:"status" gorm:"type:varchar(20);not null;default:'building'"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (EmbeddingSpace) TableName() string {
+	return "embedding_spaces"
+}
+
+// CacheKey identifies the model and version in the embedding cache
+func (s *EmbeddingSpace) CacheKey() string {
+	if s.Version == "" {
+		return s.Model
+	}
+	return s.Model + "@" + s.Version
+}
+
+// IndexName is the name of the space's partial HNSW index on chunks
+func (s *EmbeddingSpace) IndexName() string {
+	return "idx_chunks_embedding_" + strings.ReplaceAll(s.ID, "-", "")
+}
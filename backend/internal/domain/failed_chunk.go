@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+// FailedChunk is a parsed chunk whose embedding could not be generated. It
+// keeps everything needed to embed and store the chunk later.
+type FailedChunk struct {
+	ID         string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	DocumentID string    `json:"document_id" gorm:"type:varchar(36);not null;index"`
+	Content    string    `json:"content" gorm:"type:text;not null"`
+	ChunkIndex int       `json:"chunk_index" gorm:"not null"`
+	PageNumber int       `json:"page_number" gorm:"default:0"`
+	StartPos   int       `json:"start_pos"`
+	EndPos     int       `json:"end_pos"`
+	BboxX1     *float64  `json:"bbox_x1,omitempty" gorm:"type:float"`
+	BboxY1     *float64  `json:"bbox_y1,omitempty" gorm:"type:float"`
+	BboxX2     *float64  `json:"bbox_x2,omitempty" gorm:"type:float"`
+	BboxY2     *float64  `json:"bbox_y2,omitempty" gorm:"type:float"`
+	Error      string    `json:"error" gorm:"type:text"`
+	Attempts   int       `json:"attempts" gorm:"not null;default:1"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	// EmbeddingSpaceID is the space the chunk failed to be embedded in
+	EmbeddingSpaceID string `json:"embedding_space_id" gorm:"type:varchar(36);index"`
+
+	// Relations
+	Document Document `json:"-" gorm:"foreignKey:DocumentID;constraint:OnDelete:CASCADE"`
+}
+
+func (FailedChunk) TableName() string {
+	return "failed_chunks"
+}
+
+// NewFailedChunk records chunk as failed with cause
+func NewFailedChunk(chunk *Chunk, cause error) *FailedChunk {
+	return &FailedChunk{
+		ID:         chunk.ID,
+		DocumentID: chunk.DocumentID,
+		Content:    chunk.Content,
+		ChunkIndex: chunk.ChunkIndex,
+		PageNumber: chunk.PageNumber,
+		StartPos:   chunk.StartPos,
+		EndPos:     chunk.EndPos,
+		BboxX1:     chunk.BboxX1,
+		BboxY1:     chunk.BboxY1,
+		BboxX2:     chunk.BboxX2,
+		BboxY2:     chunk.BboxY2,
+		Error:      cause.Error(),
+		Attempts:   1,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+
+		EmbeddingSpaceID: chunk.EmbeddingSpaceID,
+	}
+}
+
+// ToChunk returns the chunk to embed again, without an embedding
+func (f *FailedChunk) ToChunk() *Chunk {
+	return &Chunk{
+		ID:         f.ID,
+		DocumentID: f.DocumentID,
+		Content:    f.Content,
+		ChunkIndex: f.ChunkIndex,
+		PageNumber: f.PageNumber,
+		StartPos:   f.StartPos,
+		EndPos:     f.EndPos,
+		BboxX1:     f.BboxX1,
+		BboxY1:     f.BboxY1,
+		BboxX2:     f.BboxX2,
+		BboxY2:     f.BboxY2,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+
+		EmbeddingSpaceID: f.EmbeddingSpaceID,
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// Feedback ratings
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// QueryFeedback is a user's judgement of a recorded query result. There is at
+// most one per query; submitting again replaces it.
+type QueryFeedback struct {
+	ID      string `json:"id" gorm:"type:varchar(36);primaryKey"`
+	QueryID int64  `json:"query_id" gorm:"not null;uniqueIndex"`
+	// Rating is up, down or empty
+	Rating    string        `json:"rating,omitempty" gorm:"type:varchar(10)"`
+	Comment   string        `json:"comment,omitempty" gorm:"type:text"`
+	Citations CitationMarks `json:"citations" gorm:"type:jsonb"`
+	CreatedAt time.Time     `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time     `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (QueryFeedback) TableName() string {
+	return "query_feedback"
+}
+
+// CitationMark records whether a cited chunk was relevant to the query
+type CitationMark struct {
+	ChunkID  string `json:"chunk_id"`
+	Relevant bool   `json:"relevant"`
+}
+
+// CitationMarks is a list of citation marks stored as JSONB
+type CitationMarks []CitationMark
+
+func (m CitationMarks) Value() (driver.Value, error) {
+	if m == nil {
+		return "[]", nil
+	}
+	return marshalJSON(m)
+}
+
+func (m *CitationMarks) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+// LabeledCitation is one (query, chunk, relevant) example from feedback,
+// with the scores the chunk was retrieved with
+type LabeledCitation struct {
+	QueryID      int64     `json:"query_id"`
+	Query        string    `json:"query"`
+	ChunkID      string    `json:"chunk_id"`
+	DocumentID   string    `json:"document_id"`
+	PageNumber   int       `json:"page_number"`
+	Content      string    `json:"content"`
+	Relevant     bool      `json:"relevant"`
+	Rating       string    `json:"rating,omitempty"`
+	SearchMode   string    `json:"search_mode,omitempty"`
+	Score        float64   `json:"score"`
+	VectorScore  float64   `json:"vector_score"`
+	KeywordScore float64   `json:"keyword_score"`
+	RerankScore  float64   `json:"rerank_score"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Ingestion job kinds
+const (
+	// JobKindIngest parses, embeds and stores a whole document
+	JobKindIngest = "ingest"
+	// JobKindRetryFailed embeds only the chunks that failed previously
+	JobKindRetryFailed = "retry_failed"
+	// JobKindReindex rebuilds a document's chunks with new index settings
+	JobKindReindex = "reindex"
+	// JobKindEmbedSpace fills a building embedding space with a document's chunks
+	JobKindEmbedSpace = "embed_space"
+)
+
+// Ingestion job states
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// IngestionJob is a durable unit of document processing work
+type IngestionJob struct {
+	ID          string     `json:"id" gorm:"type:varchar(36);primaryKey"`
+	DocumentID  string     `json:"document_id" gorm:"type:varchar(36);not null;index"`
+	Kind        string     `json:"kind" gorm:"type:varchar(20);not null;default:'ingest'"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_ingestion_jobs_claim,priority:1"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null;default:3"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_ingestion_jobs_claim,priority:2"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	// Settings to build the chunks with (ingest and reindex jobs)
+	IndexSettings `gorm:"embedded"`
+	// Space to fill (embed_space jobs)
+	EmbeddingSpaceID string `json:"embedding_space_id,omitempty" gorm:"type:varchar(36)"`
+
+	// Relations
+	Document Document `json:"-" gorm:"foreignKey:DocumentID;constraint:OnDelete:CASCADE"`
+}
+
+func (IngestionJob) TableName() string {
+	return "ingestion_jobs"
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList is a list of strings stored as a JSONB array
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return marshalJSON(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+// Metadata is a set of string key/value pairs stored as a JSONB object
+type Metadata map[string]string
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	return marshalJSON(m)
+}
+
+func (m *Metadata) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+func marshalJSON(v interface{}) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("unsupported JSON column type %T", value)
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// QueryHistory records one chat query for analytics
+type QueryHistory struct {
+	ID             int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	WorkspaceID    string        `json:"workspace_id" gorm:"type:varchar(36);not null;index"`
+	Query          string        `json:"query" gorm:"type:text;not null"`
+	RewrittenQuery string        `json:"rewritten_query,omitempty" gorm:"type:text"`
+	SessionID      string        `json:"session_id,omitempty" gorm:"type:varchar(36)"`
+	DocumentIDs    StringList    `json:"document_ids" gorm:"type:jsonb"`
+	SearchMode     string        `json:"search_mode" gorm:"type:varchar(20)"`
+	Response       string        `json:"response" gorm:"type:text"`
+	Citations      TurnCitations `json:"citations" gorm:"type:jsonb"`
+	// Scores are the retrieval scores of the cited chunks
+	Scores      RetrievalScores `json:"scores" gorm:"type:jsonb"`
+	ResultCount int             `json:"result_count" gorm:"default:0"`
+	// Uncited is set when the answer cited none of its sources; Citations
+	// then lists all of them
+	Uncited bool `json:"uncited" gorm:"not null;default:false"`
+	// Error is set when the query failed
+	Error string `json:"error,omitempty" gorm:"type:text"`
+
+	// Latency per stage; search covers retrieval, fusion and reranking
+	ResponseTimeMs int64 `json:"response_time_ms"`
+	EmbedMs        int64 `json:"embed_ms"`
+	SearchMs       int64 `json:"search_ms"`
+	LLMMs          int64 `json:"llm_ms" gorm:"column:llm_ms"`
+
+	// Token usage of the rewrite and answer LLM calls
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (QueryHistory) TableName() string {
+	return "query_history"
+}
+
+// RetrievalScore is the breakdown of how a cited chunk was ranked
+type RetrievalScore struct {
+	ChunkID      string  `json:"chunk_id"`
+	Score        float64 `json:"score"`
+	VectorScore  float64 `json:"vector_score"`
+	KeywordScore float64 `json:"keyword_score"`
+	RerankScore  float64 `json:"rerank_score"`
+}
+
+// RetrievalScores is a list of retrieval scores stored as JSONB
+type RetrievalScores []RetrievalScore
+
+func (s RetrievalScores) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	return marshalJSON(s)
+}
+
+func (s *RetrievalScores) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
+
+// NewRetrievalScores extracts the scores of search results
+func NewRetrievalScores(results []*SearchResult) RetrievalScores {
+	scores := make(RetrievalScores, 0, len(results))
+	for _, r := range results {
+		scores = append(scores, RetrievalScore{
+			ChunkID:      r.ID,
+			Score:        r.Score,
+			VectorScore:  r.VectorScore,
+			KeywordScore: r.KeywordScore,
+			RerankScore:  r.RerankScore,
+		})
+	}
+	return scores
+}
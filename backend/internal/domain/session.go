@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// Session is a conversation made of ordered question/answer turns
+type Session struct {
+	ID          string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	WorkspaceID string    `json:"workspace_id" gorm:"type:varchar(36);not null;index"`
+	Title       string    `json:"title" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	Turns []SessionTurn `json:"turns,omitempty" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// SessionTurn is a single question and answer within a session
+type SessionTurn struct {
+	ID             string        `json:"id" gorm:"type:varchar(36);primaryKey"`
+	SessionID      string        `json:"session_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_session_turns_order"`
+	TurnIndex      int           `json:"turn_index" gorm:"not null;uniqueIndex:idx_session_turns_order"`
+	Query          string        `json:"query" gorm:"type:text;not null"`
+	RewrittenQuery string        `json:"rewritten_query" gorm:"type:text"`
+	Answer         string        `json:"answer" gorm:"type:text"`
+	DocumentIDs    StringList    `json:"document_ids" gorm:"type:jsonb"`
+	Citations      TurnCitations `json:"citations" gorm:"type:jsonb"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (SessionTurn) TableName() string {
+	return "session_turns"
+}
+
+// TurnCitation is the stored form of a citation returned for a turn
+type TurnCitation struct {
+	ChunkID    string       `json:"chunk_id"`
+	DocumentID string       `json:"document_id"`
+	Filename   string       `json:"filename"`
+	PageNumber int          `json:"page_number"`
+	ChunkIndex int          `json:"chunk_index"`
+	Content    string       `json:"content"`
+	Bbox       *BoundingBox `json:"bbox,omitempty"`
+	Score      float64      `json:"score"`
+}
+
+// TurnCitations is a list of citations stored as JSONB
+type TurnCitations []TurnCitation
+
+func (c TurnCitations) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	return marshalJSON(c)
+}
+
+func (c *TurnCitations) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// NewTurnCitations converts search results into their stored form
+func NewTurnCitations(results []*SearchResult) TurnCitations {
+	citations := make(TurnCitations, 0, len(results))
+	for _, r := range results {
+		citations = append(citations, TurnCitation{
+			ChunkID:    r.ID,
+			DocumentID: r.DocumentID,
+			Filename:   r.Filename,
+			PageNumber: r.PageNumber,
+			ChunkIndex: r.ChunkIndex,
+			Content:    r.Content,
+			Bbox:       r.GetBoundingBox(),
+			Score:      r.Score,
+		})
+	}
+	return citations
+}
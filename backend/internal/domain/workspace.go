@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// DefaultWorkspaceID is the workspace requests use when none is selected.
+// It is created by the migrations and cannot be deleted.
+const DefaultWorkspaceID = "default"
+
+// Workspace is a tenant. Documents, chunks, sessions and recorded queries
+// belong to exactly one workspace and are only visible within it.
+type Workspace struct {
+	ID   string `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Name string `json:"name" gorm:"type:varchar(255);not null"`
+	// MaxDocuments and MaxStorageBytes override the configured quotas;
+	// 0 means unlimited
+	MaxDocuments    *int      `json:"max_documents,omitempty"`
+	MaxStorageBytes *int64    `json:"max_storage_bytes,omitempty"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (Workspace) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceUsage is what a workspace stores, counted against its quotas.
+// Aliases count as documents but not as storage, since they share a file.
+type WorkspaceUsage struct {
+	Documents    int64 `json:"documents"`
+	StorageBytes int64 `json:"storage_bytes"`
+}
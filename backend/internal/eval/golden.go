@@ -0,0 +1,112 @@
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+// GoldenQuestion is one line of a golden set: a question with the sources
+// that should be retrieved and/or keywords the answer should contain
+type GoldenQuestion struct {
+	ID       string `json:"id"`
+	Question string `json:"question"`
+	// DocumentIDs and CollectionIDs scope the search; with neither every
+	// indexed document is searched
+	DocumentIDs    []string         `json:"document_ids,omitempty"`
+	CollectionIDs  []string         `json:"collection_ids,omitempty"`
+	Expected       []ExpectedSource `json:"expected,omitempty"`
+	AnswerKeywords []string         `json:"answer_keywords,omitempty"`
+}
+
+// ExpectedSource identifies a relevant document, optionally narrowed to a
+// page. Filename can be used instead of document_id so golden sets work
+// across environments where documents have different IDs.
+type ExpectedSource struct {
+	DocumentID string `json:"document_id,omitempty"`
+	Filename   string `json:"filename,omitempty"`
+	// Page is 1-based; 0 matches any page of the document
+	Page int `json:"page,omitempty"`
+}
+
+// Matches reports whether a retrieved chunk comes from the expected source
+func (e ExpectedSource) Matches(result *domain.SearchResult) bool {
+	if e.DocumentID != "" && e.DocumentID != result.DocumentID {
+		return false
+	}
+	if e.Filename != "" && !strings.EqualFold(e.Filename, result.Filename) {
+		return false
+	}
+	return e.Page == 0 || e.Page == result.PageNumber
+}
+
+func (e ExpectedSource) String() string {
+	doc := e.DocumentID
+	if doc == "" {
+		doc = e.Filename
+	}
+	if e.Page == 0 {
+		return doc
+	}
+	return fmt.Sprintf("%s p.%d", doc, e.Page)
+}
+
+// LoadGolden reads a JSONL golden set. Blank lines and lines starting with #
+// are skipped; questions without an ID are named after their line number.
+func LoadGolden(path string) ([]*GoldenQuestion, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open golden set: %w", err)
+	}
+	defer file.Close()
+
+	var questions []*GoldenQuestion
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var q GoldenQuestion
+		if err := json.Unmarshal([]byte(line), &q); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if strings.TrimSpace(q.Question) == "" {
+			return nil, fmt.Errorf("line %d: question is empty", lineNo)
+		}
+		if len(q.Expected) == 0 && len(q.AnswerKeywords) == 0 {
+			return nil, fmt.Errorf("line %d: expected or answer_keywords required", lineNo)
+		}
+		for _, source := range q.Expected {
+			if source.DocumentID == "" && source.Filename == "" {
+				return nil, fmt.Errorf("line %d: expected source needs document_id or filename", lineNo)
+			}
+		}
+
+		if q.ID == "" {
+			q.ID = fmt.Sprintf("line-%d", lineNo)
+		}
+		if seen[q.ID] {
+			return nil, fmt.Errorf("line %d: duplicate id %q", lineNo, q.ID)
+		}
+		seen[q.ID] = true
+
+		questions = append(questions, &q)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read golden set: %w", err)
+	}
+
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("golden set %s has no questions", path)
+	}
+	return questions, nil
+}
@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+// recallAtK is the fraction of expected sources matched by the top k results
+func recallAtK(expected []ExpectedSource, results []*domain.SearchResult, k int) float64 {
+	if len(expected) == 0 {
+		return 0
+	}
+	if k < len(results) {
+		results = results[:k]
+	}
+
+	found := 0
+	for _, source := range expected {
+		for _, result := range results {
+			if source.Matches(result) {
+				found++
+				break
+			}
+		}
+	}
+	return float64(found) / float64(len(expected))
+}
+
+// reciprocalRank is 1/rank of the first result matching any expected source,
+// or 0 if none does
+func reciprocalRank(expected []ExpectedSource, results []*domain.SearchResult) float64 {
+	for i, result := range results {
+		if matchesAny(expected, result) {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// citationPrecision is the fraction of citations that come from an expected
+// source. ok is false when there were no citations to judge.
+func citationPrecision(expected []ExpectedSource, citations []*domain.SearchResult) (precision float64, ok bool) {
+	if len(citations) == 0 {
+		return 0, false
+	}
+
+	relevant := 0
+	for _, citation := range citations {
+		if matchesAny(expected, citation) {
+			relevant++
+		}
+	}
+	return float64(relevant) / float64(len(citations)), true
+}
+
+// keywordHits returns the keywords found in the answer, case-insensitively
+func keywordHits(keywords []string, answer string) []string {
+	answer = strings.ToLower(answer)
+
+	var hits []string
+	for _, keyword := range keywords {
+		if strings.Contains(answer, strings.ToLower(keyword)) {
+			hits = append(hits, keyword)
+		}
+	}
+	return hits
+}
+
+func matchesAny(expected []ExpectedSource, result *domain.SearchResult) bool {
+	for _, source := range expected {
+		if source.Matches(result) {
+			return true
+		}
+	}
+	return false
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the p-th percentile (0-1) by linear interpolation
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
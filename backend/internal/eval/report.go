@@ -0,0 +1,201 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Thresholds decide when a difference between runs is a regression
+type Thresholds struct {
+	// Quality is the largest allowed absolute drop of a 0-1 metric
+	Quality float64
+	// Latency is the largest allowed relative increase of a p95 latency
+	Latency float64
+}
+
+// MetricDelta compares one summary metric between two runs
+type MetricDelta struct {
+	Name      string  `json:"name"`
+	Base      float64 `json:"base"`
+	Head      float64 `json:"head"`
+	Delta     float64 `json:"delta"`
+	Regressed bool    `json:"regressed"`
+}
+
+// QuestionDelta is a per-question metric that changed between runs
+type QuestionDelta struct {
+	ID       string  `json:"id"`
+	Question string  `json:"question"`
+	Metric   string  `json:"metric"`
+	Base     float64 `json:"base"`
+	Head     float64 `json:"head"`
+}
+
+// Diff is the comparison of a run (head) against a baseline (base)
+type Diff struct {
+	Metrics      []MetricDelta   `json:"metrics"`
+	Regressions  []QuestionDelta `json:"regressions"`
+	Improvements []QuestionDelta `json:"improvements"`
+	// SettingsChanged lists settings that differ, as "name: base -> head"
+	SettingsChanged []string `json:"settings_changed"`
+}
+
+// Regressed reports whether any summary metric got worse beyond the thresholds
+func (d *Diff) Regressed() bool {
+	for _, m := range d.Metrics {
+		if m.Regressed {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare diffs head against base
+func Compare(base, head *Run, t Thresholds) *Diff {
+	diff := &Diff{SettingsChanged: settingsChanged(base.Settings, head.Settings)}
+
+	quality := func(name string, b, h float64) {
+		diff.Metrics = append(diff.Metrics, MetricDelta{
+			Name: name, Base: b, Head: h, Delta: h - b,
+			Regressed: b-h > t.Quality,
+		})
+	}
+	latency := func(name string, b, h float64) {
+		diff.Metrics = append(diff.Metrics, MetricDelta{
+			Name: name, Base: b, Head: h, Delta: h - b,
+			Regressed: b > 0 && (h-b)/b > t.Latency,
+		})
+	}
+
+	quality("recall@k", base.Summary.RecallAtK, head.Summary.RecallAtK)
+	quality("mrr", base.Summary.MRR, head.Summary.MRR)
+	quality("citation_precision", base.Summary.CitationPrecision, head.Summary.CitationPrecision)
+	quality("keyword_hit_rate", base.Summary.KeywordHitRate, head.Summary.KeywordHitRate)
+	latency("search_p95_ms", base.Summary.SearchP95Ms, head.Summary.SearchP95Ms)
+	latency("query_p95_ms", base.Summary.QueryP95Ms, head.Summary.QueryP95Ms)
+
+	// Per-question changes, matched by ID
+	baseByID := make(map[string]*QuestionResult, len(base.Results))
+	for _, result := range base.Results {
+		baseByID[result.ID] = result
+	}
+	for _, h := range head.Results {
+		b, ok := baseByID[h.ID]
+		if !ok {
+			continue
+		}
+		diff.compareQuestion(h, "recall@k", b.RecallAtK, h.RecallAtK)
+		diff.compareQuestion(h, "reciprocal_rank", b.ReciprocalRank, h.ReciprocalRank)
+		diff.compareQuestion(h, "citation_precision", b.CitationPrecision, h.CitationPrecision)
+		diff.compareQuestion(h, "keyword_hit_rate", b.KeywordHitRate, h.KeywordHitRate)
+	}
+
+	return diff
+}
+
+func (d *Diff) compareQuestion(result *QuestionResult, metric string, base, head *float64) {
+	if base == nil || head == nil || *base == *head {
+		return
+	}
+
+	delta := QuestionDelta{
+		ID:       result.ID,
+		Question: result.Question,
+		Metric:   metric,
+		Base:     *base,
+		Head:     *head,
+	}
+	if *head < *base {
+		d.Regressions = append(d.Regressions, delta)
+	} else {
+		d.Improvements = append(d.Improvements, delta)
+	}
+}
+
+func settingsChanged(base, head Settings) []string {
+	var changed []string
+	add := func(name string, b, h interface{}) {
+		if fmt.Sprint(b) != fmt.Sprint(h) {
+			changed = append(changed, fmt.Sprintf("%s: %v -> %v", name, b, h))
+		}
+	}
+
+	add("k", base.K, head.K)
+	add("search_mode", base.SearchMode, head.SearchMode)
+	add("vector_weight", base.VectorWeight, head.VectorWeight)
+	add("similarity_threshold", base.SimilarityThreshold, head.SimilarityThreshold)
+	add("rerank_provider", base.RerankProvider, head.RerankProvider)
+	add("rerank_model", base.RerankModel, head.RerankModel)
+	add("rerank_candidates", base.RerankCandidates, head.RerankCandidates)
+	add("rerank_top_n", base.RerankTopN, head.RerankTopN)
+	add("rerank_min_score", base.RerankMinScore, head.RerankMinScore)
+	add("llm_provider", base.LLMProvider, head.LLMProvider)
+	add("llm_model", base.LLMModel, head.LLMModel)
+	add("embedding_provider", base.EmbeddingProvider, head.EmbeddingProvider)
+	add("embedding_model", base.EmbeddingModel, head.EmbeddingModel)
+	add("chunk_size", base.ChunkSize, head.ChunkSize)
+	add("chunk_overlap", base.ChunkOverlap, head.ChunkOverlap)
+	add("skip_answers", base.SkipAnswers, head.SkipAnswers)
+	return changed
+}
+
+// PrintSummary writes a human-readable summary of a run
+func PrintSummary(w io.Writer, run *Run) {
+	s := run.Summary
+	fmt.Fprintf(w, "Run %q (%s)\n", run.Label, run.StartedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "  questions:           %d (%d errors)\n", s.Questions, s.Errors)
+	fmt.Fprintf(w, "  recall@%d:            %.3f  (%d questions)\n", run.Settings.K, s.RecallAtK, s.RetrievalQuestions)
+	fmt.Fprintf(w, "  MRR:                 %.3f\n", s.MRR)
+	if !run.Settings.SkipAnswers {
+		fmt.Fprintf(w, "  citation precision:  %.3f\n", s.CitationPrecision)
+		fmt.Fprintf(w, "  keyword hit rate:    %.3f  (%d questions)\n", s.KeywordHitRate, s.KeywordQuestions)
+		fmt.Fprintf(w, "  query latency:       p50 %.0fms, p95 %.0fms\n", s.QueryP50Ms, s.QueryP95Ms)
+	}
+	fmt.Fprintf(w, "  search latency:      p50 %.0fms, p95 %.0fms\n", s.SearchP50Ms, s.SearchP95Ms)
+}
+
+// PrintDiff writes a human-readable comparison of two runs
+func PrintDiff(w io.Writer, base, head *Run, diff *Diff) {
+	fmt.Fprintf(w, "Base %q vs head %q\n\n", base.Label, head.Label)
+
+	if len(diff.SettingsChanged) > 0 {
+		fmt.Fprintln(w, "Settings changed:")
+		for _, change := range diff.SettingsChanged {
+			fmt.Fprintf(w, "  %s\n", change)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%-20s %10s %10s %10s\n", "metric", "base", "head", "delta")
+	for _, m := range diff.Metrics {
+		flag := ""
+		if m.Regressed {
+			flag = "  REGRESSION"
+		}
+		fmt.Fprintf(w, "%-20s %10.3f %10.3f %+10.3f%s\n", m.Name, m.Base, m.Head, m.Delta, flag)
+	}
+
+	printQuestionDeltas(w, "Questions that got worse", diff.Regressions)
+	printQuestionDeltas(w, "Questions that got better", diff.Improvements)
+}
+
+func printQuestionDeltas(w io.Writer, title string, deltas []QuestionDelta) {
+	if len(deltas) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n%s (%d):\n", title, len(deltas))
+	for _, d := range deltas {
+		fmt.Fprintf(w, "  %-12s %-18s %.3f -> %.3f  %s\n", d.ID, d.Metric, d.Base, d.Head, truncate(d.Question, 60))
+	}
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
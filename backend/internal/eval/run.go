@@ -0,0 +1,352 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/service"
+	"github.com/pdf-rag-system/backend/pkg/config"
+)
+
+// Options controls an evaluation run
+type Options struct {
+	Label string
+	// K is the cutoff for recall@k
+	K int
+	// SearchMode and VectorWeight override the configured defaults when set
+	SearchMode   string
+	VectorWeight *float64
+	// SkipAnswers only evaluates retrieval, without calling the LLM
+	SkipAnswers bool
+	// Workspace is the workspace whose documents are searched
+	Workspace string
+}
+
+// Settings is a snapshot of the configuration a run used, so diffs can show
+// what changed between runs
+type Settings struct {
+	K                   int     `json:"k"`
+	SearchMode          string  `json:"search_mode"`
+	VectorWeight        float64 `json:"vector_weight"`
+	SimilarityThreshold float64 `json:"similarity_threshold"`
+	RerankProvider      string  `json:"rerank_provider"`
+	RerankModel         string  `json:"rerank_model,omitempty"`
+	RerankCandidates    int     `json:"rerank_candidates"`
+	RerankTopN          int     `json:"rerank_top_n"`
+	RerankMinScore      float64 `json:"rerank_min_score"`
+	LLMProvider         string  `json:"llm_provider"`
+	LLMModel            string  `json:"llm_model"`
+	EmbeddingProvider   string  `json:"embedding_provider"`
+	EmbeddingModel      string  `json:"embedding_model"`
+	ChunkSize           int     `json:"chunk_size"`
+	ChunkOverlap        int     `json:"chunk_overlap"`
+	SkipAnswers         bool    `json:"skip_answers"`
+}
+
+// Run is the result of evaluating a golden set
+type Run struct {
+	Label     string            `json:"label"`
+	StartedAt time.Time         `json:"started_at"`
+	Settings  Settings          `json:"settings"`
+	Summary   Summary           `json:"summary"`
+	Results   []*QuestionResult `json:"results"`
+}
+
+// Summary aggregates the per-question metrics. Retrieval metrics cover the
+// questions with expected sources, keyword hit rate those with keywords.
+type Summary struct {
+	Questions          int     `json:"questions"`
+	Errors             int     `json:"errors"`
+	RetrievalQuestions int     `json:"retrieval_questions"`
+	RecallAtK          float64 `json:"recall_at_k"`
+	MRR                float64 `json:"mrr"`
+	CitationPrecision  float64 `json:"citation_precision"`
+	KeywordQuestions   int     `json:"keyword_questions"`
+	KeywordHitRate     float64 `json:"keyword_hit_rate"`
+	SearchP50Ms        float64 `json:"search_p50_ms"`
+	SearchP95Ms        float64 `json:"search_p95_ms"`
+	QueryP50Ms         float64 `json:"query_p50_ms"`
+	QueryP95Ms         float64 `json:"query_p95_ms"`
+}
+
+// QuestionResult is the evaluation of one golden question
+type QuestionResult struct {
+	ID       string `json:"id"`
+	Question string `json:"question"`
+	Error    string `json:"error,omitempty"`
+
+	// Retrieval metrics; nil when the question has no expected sources
+	RecallAtK      *float64 `json:"recall_at_k,omitempty"`
+	ReciprocalRank *float64 `json:"reciprocal_rank,omitempty"`
+	// Retrieved are the top k sources of the retrieval candidates
+	Retrieved []RetrievedSource `json:"retrieved"`
+
+	// Answer metrics; nil when answers were skipped or not applicable
+	CitationPrecision *float64          `json:"citation_precision,omitempty"`
+	KeywordHitRate    *float64          `json:"keyword_hit_rate,omitempty"`
+	MissingKeywords   []string          `json:"missing_keywords,omitempty"`
+	Citations         []RetrievedSource `json:"citations,omitempty"`
+	Answer            string            `json:"answer,omitempty"`
+
+	SearchMs int64 `json:"search_ms"`
+	QueryMs  int64 `json:"query_ms,omitempty"`
+}
+
+// RetrievedSource is the location of a retrieved or cited chunk
+type RetrievedSource struct {
+	DocumentID string  `json:"document_id"`
+	Filename   string  `json:"filename"`
+	Page       int     `json:"page"`
+	Score      float64 `json:"score"`
+	Relevant   bool    `json:"relevant"`
+}
+
+// Runner evaluates golden questions against the chat service
+type Runner struct {
+	chat     *service.ChatService
+	sessions *service.SessionService
+	config   *config.Config
+	// defaultDocIDs scopes questions that do not name their documents
+	defaultDocIDs []string
+}
+
+func NewRunner(chat *service.ChatService, sessions *service.SessionService, cfg *config.Config, defaultDocIDs []string) *Runner {
+	return &Runner{
+		chat:          chat,
+		sessions:      sessions,
+		config:        cfg,
+		defaultDocIDs: defaultDocIDs,
+	}
+}
+
+// Run evaluates the questions one at a time, so latencies are not skewed by
+// concurrent load. Questions that fail are reported and counted as misses.
+func (r *Runner) Run(ctx context.Context, questions []*GoldenQuestion, opts Options) *Run {
+	if opts.K <= 0 {
+		opts.K = 5
+	}
+
+	run := &Run{
+		Label:     opts.Label,
+		StartedAt: time.Now(),
+		Settings:  r.settings(opts),
+	}
+
+	for i, q := range questions {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(questions), q.ID)
+		result := r.evaluate(ctx, q, opts)
+		if result.Error != "" {
+			fmt.Printf("  ERROR: %s\n", result.Error)
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	run.Summary = summarize(run.Results)
+	return run
+}
+
+func (r *Runner) evaluate(ctx context.Context, q *GoldenQuestion, opts Options) *QuestionResult {
+	result := &QuestionResult{ID: q.ID, Question: q.Question}
+
+	// Start from zero so a failed question counts as a miss
+	if len(q.Expected) > 0 {
+		result.RecallAtK, result.ReciprocalRank = new(float64), new(float64)
+	}
+	if len(q.AnswerKeywords) > 0 && !opts.SkipAnswers {
+		result.KeywordHitRate = new(float64)
+	}
+
+	docIDs := q.DocumentIDs
+	if len(docIDs) == 0 && len(q.CollectionIDs) == 0 {
+		docIDs = r.defaultDocIDs
+	}
+	req := &service.QueryRequest{
+		Query:         q.Question,
+		WorkspaceID:   opts.Workspace,
+		DocumentIDs:   docIDs,
+		CollectionIDs: q.CollectionIDs,
+		SearchMode:    opts.SearchMode,
+		VectorWeight:  opts.VectorWeight,
+	}
+
+	searchStart := time.Now()
+	candidates, err := r.chat.Search(ctx, req)
+	result.SearchMs = time.Since(searchStart).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("search failed: %v", err)
+		return result
+	}
+
+	topK := candidates
+	if len(topK) > opts.K {
+		topK = topK[:opts.K]
+	}
+	result.Retrieved = sources(q.Expected, topK)
+
+	if len(q.Expected) > 0 {
+		recall := recallAtK(q.Expected, candidates, opts.K)
+		rr := reciprocalRank(q.Expected, candidates)
+		result.RecallAtK = &recall
+		result.ReciprocalRank = &rr
+	}
+
+	if opts.SkipAnswers {
+		return result
+	}
+
+	queryStart := time.Now()
+	resp, err := r.chat.Query(ctx, req)
+	result.QueryMs = time.Since(queryStart).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("query failed: %v", err)
+		return result
+	}
+
+	// Every query starts a session; evaluation sessions are not kept
+	if resp.SessionID != "" {
+		if err := r.sessions.Delete(ctx, opts.Workspace, resp.SessionID); err != nil {
+			fmt.Printf("  WARNING: Failed to delete evaluation session %s: %v\n", resp.SessionID, err)
+		}
+	}
+
+	result.Answer = resp.Answer
+	result.Citations = sources(q.Expected, resp.Citations)
+
+	if len(q.Expected) > 0 {
+		if precision, ok := citationPrecision(q.Expected, resp.Citations); ok {
+			result.CitationPrecision = &precision
+		}
+	}
+
+	if len(q.AnswerKeywords) > 0 {
+		hits := keywordHits(q.AnswerKeywords, resp.Answer)
+		rate := float64(len(hits)) / float64(len(q.AnswerKeywords))
+		result.KeywordHitRate = &rate
+		result.MissingKeywords = missing(q.AnswerKeywords, hits)
+	}
+
+	return result
+}
+
+func (r *Runner) settings(opts Options) Settings {
+	mode := opts.SearchMode
+	if mode == "" {
+		mode = r.config.Search.Mode
+	}
+	vectorWeight := r.config.Search.VectorWeight
+	if opts.VectorWeight != nil {
+		vectorWeight = *opts.VectorWeight
+	}
+
+	return Settings{
+		K:                   opts.K,
+		SearchMode:          mode,
+		VectorWeight:        vectorWeight,
+		SimilarityThreshold: r.config.Search.SimilarityThreshold,
+		RerankProvider:      r.config.Rerank.Provider,
+		RerankModel:         r.config.Rerank.Model,
+		RerankCandidates:    r.config.Rerank.CandidateCount,
+		RerankTopN:          r.config.Rerank.TopN,
+		RerankMinScore:      r.config.Rerank.MinScore,
+		LLMProvider:         r.config.LLM.Provider,
+		LLMModel:            r.config.LLM.Model,
+		EmbeddingProvider:   r.config.Embedding.Provider,
+		EmbeddingModel:      r.config.Embedding.Model,
+		ChunkSize:           r.config.Chunking.Size,
+		ChunkOverlap:        r.config.Chunking.Overlap,
+		SkipAnswers:         opts.SkipAnswers,
+	}
+}
+
+func summarize(results []*QuestionResult) Summary {
+	summary := Summary{Questions: len(results)}
+
+	var recalls, rrs, precisions, keywordRates, searchMs, queryMs []float64
+	for _, result := range results {
+		if result.Error != "" {
+			summary.Errors++
+		}
+		if result.RecallAtK != nil {
+			recalls = append(recalls, *result.RecallAtK)
+			rrs = append(rrs, *result.ReciprocalRank)
+		}
+		if result.CitationPrecision != nil {
+			precisions = append(precisions, *result.CitationPrecision)
+		}
+		if result.KeywordHitRate != nil {
+			keywordRates = append(keywordRates, *result.KeywordHitRate)
+		}
+		searchMs = append(searchMs, float64(result.SearchMs))
+		if result.QueryMs > 0 {
+			queryMs = append(queryMs, float64(result.QueryMs))
+		}
+	}
+
+	summary.RetrievalQuestions = len(recalls)
+	summary.RecallAtK = mean(recalls)
+	summary.MRR = mean(rrs)
+	summary.CitationPrecision = mean(precisions)
+	summary.KeywordQuestions = len(keywordRates)
+	summary.KeywordHitRate = mean(keywordRates)
+	summary.SearchP50Ms = percentile(searchMs, 0.5)
+	summary.SearchP95Ms = percentile(searchMs, 0.95)
+	summary.QueryP50Ms = percentile(queryMs, 0.5)
+	summary.QueryP95Ms = percentile(queryMs, 0.95)
+	return summary
+}
+
+func sources(expected []ExpectedSource, results []*domain.SearchResult) []RetrievedSource {
+	out := make([]RetrievedSource, 0, len(results))
+	for _, result := range results {
+		out = append(out, RetrievedSource{
+			DocumentID: result.DocumentID,
+			Filename:   result.Filename,
+			Page:       result.PageNumber,
+			Score:      result.Score,
+			Relevant:   matchesAny(expected, result),
+		})
+	}
+	return out
+}
+
+func missing(keywords, hits []string) []string {
+	found := make(map[string]bool, len(hits))
+	for _, hit := range hits {
+		found[hit] = true
+	}
+
+	var out []string
+	for _, keyword := range keywords {
+		if !found[keyword] {
+			out = append(out, keyword)
+		}
+	}
+	return out
+}
+
+// SaveRun writes a run as indented JSON
+func SaveRun(path string, run *Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadRun reads a run written by SaveRun
+func LoadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run %s: %w", path, err)
+	}
+	return &run, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error
+	return &key, err
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error
+	return &key, err
+}
+
+// List returns all keys, revoked ones included, newest first
+func (r *APIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// ListInWorkspace returns the keys bound to a workspace, newest first
+func (r *APIKeyRepository) ListInWorkspace(ctx context.Context, workspaceID string) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.db.WithContext(ctx).
+		Scopes(inWorkspace(workspaceID)).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke marks the key revoked; revoking twice keeps the first time
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
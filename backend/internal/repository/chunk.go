@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"unicode"
 
+	"github.com/google/uuid"
 	"github.com/pdf-rag-system/backend/internal/domain"
 	"github.com/pgvector/pgvector-go"
 	"gorm.io/gorm"
@@ -25,6 +29,65 @@ func (r *ChunkRepository) BatchCreate(ctx context.Context, chunks []*domain.Chun
 	return r.db.WithContext(ctx).CreateInBatches(chunks, 100).Error
 }
 
+// ReplaceForDocument swaps the document's chunks and failed chunks in an
+// embedding space for the given sets in a single transaction, so readers see
+// either the old set or the complete new one. Other spaces are left alone.
+func (r *ChunkRepository) ReplaceForDocument(ctx context.Context, documentID, spaceID string, chunks []*domain.Chunk, failed []*domain.FailedChunk) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ? AND embedding_space_id = ?", documentID, spaceID).Delete(&domain.Chunk{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("document_id = ? AND embedding_space_id = ?", documentID, spaceID).Delete(&domain.FailedChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) > 0 {
+			if err := tx.CreateInBatches(chunks, 100).Error; err != nil {
+				return err
+			}
+		}
+		if len(failed) > 0 {
+			return tx.CreateInBatches(failed, 100).Error
+		}
+		return nil
+	})
+}
+
+// ResolveFailed stores chunks recovered by a retry and replaces the document's
+// failed chunks in the space with those that failed again.
+func (r *ChunkRepository) ResolveFailed(ctx context.Context, documentID, spaceID string, recovered []*domain.Chunk, stillFailed []*domain.FailedChunk) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ? AND embedding_space_id = ?", documentID, spaceID).Delete(&domain.FailedChunk{}).Error; err != nil {
+			return err
+		}
+		if len(recovered) > 0 {
+			if err := tx.CreateInBatches(recovered, 100).Error; err != nil {
+				return err
+			}
+		}
+		if len(stillFailed) > 0 {
+			return tx.CreateInBatches(stillFailed, 100).Error
+		}
+		return nil
+	})
+}
+
+func (r *ChunkRepository) GetFailedByDocumentID(ctx context.Context, documentID, spaceID string) ([]*domain.FailedChunk, error) {
+	var failed []*domain.FailedChunk
+	err := r.db.WithContext(ctx).
+		Where("document_id = ? AND embedding_space_id = ?", documentID, spaceID).
+		Order("chunk_index ASC").
+		Find(&failed).Error
+	return failed, err
+}
+
+func (r *ChunkRepository) CountByDocumentID(ctx context.Context, documentID, spaceID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Chunk{}).
+		Where("document_id = ? AND embedding_space_id = ?", documentID, spaceID).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *ChunkRepository) GetByDocumentID(ctx context.Context, documentID string) ([]*domain.Chunk, error) {
 	var chunks []*domain.Chunk
 	err := r.db.WithContext(ctx).
@@ -34,7 +97,13 @@ func (r *ChunkRepository) GetByDocumentID(ctx context.Context, documentID string
 	return chunks, err
 }
 
-func (r *ChunkRepository) VectorSearch(ctx context.Context, embedding []float64, documentIDs []string, limit int) ([]*domain.SearchResult, error) {
+// VectorSearch ranks the chunks of an embedding space by cosine similarity.
+// The embeddings are cast to the space's dimension and the space ID is
+// inlined so the planner can use the space's partial HNSW index. Only chunks
+// of the workspace are searched, whatever document IDs are given; nil
+// documentIDs searches all of them. The filter narrows the documents before
+// ranking.
+func (r *ChunkRepository) VectorSearch(ctx context.Context, workspaceID string, space *domain.EmbeddingSpace, embedding []float64, documentIDs []string, filter *domain.DocumentFilter, limit int) ([]*domain.SearchResult, error) {
 	var results []*domain.SearchResult
 
 	// Convert float64 to float32 for pgvector
@@ -44,7 +113,12 @@ func (r *ChunkRepository) VectorSearch(ctx context.Context, embedding []float64,
 	}
 	vector := pgvector.NewVector(embedding32)
 
-	query := `
+	if _, err := uuid.Parse(space.ID); err != nil {
+		return nil, fmt.Errorf("invalid embedding space ID %q: %w", space.ID, err)
+	}
+
+	documents, documentArgs := documentCondition(workspaceID, documentIDs, filter)
+	query := fmt.Sprintf(`
 		SELECT
 			c.id,
 			c.document_id,
@@ -58,22 +132,180 @@ func (r *ChunkRepository) VectorSearch(ctx context.Context, embedding []float64,
 			c.bbox_x2,
 			c.bbox_y2,
 			d.filename,
-			1 - (c.embedding <=> ?) as score
+			1 - (c.embedding::vector(%[1]d) <=> ?::vector(%[1]d)) as score
 		FROM chunks c
 		JOIN documents d ON c.document_id = d.id
-		WHERE c.document_id IN (?)
-		ORDER BY c.embedding <=> ?
+		WHERE c.embedding_space_id = '%[2]s'
+		  AND c.workspace_id = ?
+		  %[3]s
+		ORDER BY c.embedding::vector(%[1]d) <=> ?::vector(%[1]d)
 		LIMIT ?
-	`
+	`, space.Dimension, space.ID, documents)
 
-	err := r.db.WithContext(ctx).Raw(query, vector, documentIDs, vector, limit).Scan(&results).Error
+	args := append([]interface{}{vector, workspaceID}, documentArgs...)
+	args = append(args, vector, limit)
+	err := r.db.WithContext(ctx).Raw(query, args...).Scan(&results).Error
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
 
+	for _, result := range results {
+		result.VectorScore = result.Score
+	}
+
 	return results, nil
 }
 
+// KeywordSearch ranks chunks by full-text match against the query terms.
+// Any term may match; chunks matching more (and rarer, denser) terms rank
+// higher, with the rank normalized by chunk length and scaled to 0-1.
+// Only chunks of the given embedding space are searched, so documents stored
+// in several spaces are not counted twice, and only those of the workspace
+// (all of them for nil documentIDs) that match the filter.
+func (r *ChunkRepository) KeywordSearch(ctx context.Context, workspaceID, spaceID, queryText string, documentIDs []string, filter *domain.DocumentFilter, limit int) ([]*domain.SearchResult, error) {
+	var results []*domain.SearchResult
+
+	terms := keywordTerms(queryText)
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	// Chunks matching an identifier-like term are flagged, as only those
+	// hits are trusted without semantic similarity
+	identifierMatch := "false"
+	args := []interface{}{strings.Join(terms, " or ")}
+	if identifiers := identifierTerms(terms); len(identifiers) > 0 {
+		identifierMatch = "c.content_tsv @@ websearch_to_tsquery('english', ?)"
+		args = append(args, strings.Join(identifiers, " or "))
+	}
+
+	documents, documentArgs := documentCondition(workspaceID, documentIDs, filter)
+	query := fmt.Sprintf(`
+		WITH q AS (SELECT websearch_to_tsquery('english', ?) AS query)
+		SELECT
+			c.id,
+			c.document_id,
+			c.content,
+			c.chunk_index,
+			c.page_number,
+			c.start_pos,
+			c.end_pos,
+			c.bbox_x1,
+			c.bbox_y1,
+			c.bbox_x2,
+			c.bbox_y2,
+			d.filename,
+			ts_rank_cd(c.content_tsv, q.query, 1|32) as score,
+			%s as identifier_match
+		FROM chunks c
+		JOIN documents d ON c.document_id = d.id
+		CROSS JOIN q
+		WHERE c.embedding_space_id = ?
+		  AND c.workspace_id = ?
+		  %s
+		  AND c.content_tsv @@ q.query
+		ORDER BY score DESC
+		LIMIT ?
+	`, identifierMatch, documents)
+
+	args = append(args, spaceID, workspaceID)
+	args = append(args, documentArgs...)
+	args = append(args, limit)
+	err := r.db.WithContext(ctx).Raw(query, args...).Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", err)
+	}
+
+	for _, result := range results {
+		result.KeywordScore = result.Score
+	}
+
+	return results, nil
+}
+
+// documentCondition restricts a search to documentIDs (all documents if
+// nil) matching the filter. The filter applies to the requested documents,
+// so an alias is matched on its own tags and metadata.
+func documentCondition(workspaceID string, documentIDs []string, filter *domain.DocumentFilter) (string, []interface{}) {
+	if documentIDs == nil && filter.Empty() {
+		return "", nil
+	}
+
+	conditions := []string{"workspace_id = ?"}
+	args := []interface{}{workspaceID}
+	if documentIDs != nil {
+		conditions = append(conditions, "id IN (?)")
+		args = append(args, documentIDs)
+	}
+	if !filter.Empty() {
+		if tags := domain.NormalizeTags(filter.Tags); len(tags) > 0 {
+			// One containment test per tag, so the GIN index can be used
+			matches := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				tagJSON, _ := json.Marshal([]string{tag})
+				matches = append(matches, "tags @> ?::jsonb")
+				args = append(args, string(tagJSON))
+			}
+			conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+		}
+		if author := strings.TrimSpace(filter.Author); author != "" {
+			conditions = append(conditions, "LOWER(author) = LOWER(?)")
+			args = append(args, author)
+		}
+		if filter.CreatedAfter != "" {
+			conditions = append(conditions, "creation_date >= ?::date")
+			args = append(args, filter.CreatedAfter)
+		}
+		if filter.CreatedBefore != "" {
+			conditions = append(conditions, "creation_date < ?::date + 1")
+			args = append(args, filter.CreatedBefore)
+		}
+		if len(filter.Metadata) > 0 {
+			metadataJSON, _ := json.Marshal(filter.Metadata)
+			conditions = append(conditions, "metadata @> ?::jsonb")
+			args = append(args, string(metadataJSON))
+		}
+	}
+
+	// Aliases search the chunks of the document they duplicate
+	return "AND c.document_id IN (SELECT COALESCE(alias_of, id) FROM documents WHERE " + strings.Join(conditions, " AND ") + ")", args
+}
+
+// keywordTerms splits free text into search terms, keeping identifiers such
+// as part numbers ("AB-1234") and clause IDs ("4.2.1") intact.
+func keywordTerms(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.' && r != '/'
+	})
+
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		// Leading "-" would negate the term in websearch_to_tsquery
+		term := strings.Trim(field, "-_./")
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// identifierTerms returns the terms that look like identifiers rather than
+// words: those containing digits or the separators "-", "." and "/"
+func identifierTerms(terms []string) []string {
+	var identifiers []string
+	for _, term := range terms {
+		if strings.ContainsAny(term, "0123456789-./") {
+			identifiers = append(identifiers, term)
+		}
+	}
+	return identifiers
+}
+
 func (r *ChunkRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
-	return r.db.WithContext(ctx).Where("document_id = ?", documentID).Delete(&domain.Chunk{}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", documentID).Delete(&domain.FailedChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("document_id = ?", documentID).Delete(&domain.Chunk{}).Error
+	})
 }
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeywordTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"warranty of part AB-1234?", []string{"warranty", "of", "part", "AB-1234"}},
+		{"what does clause 4.2.1 say", []string{"what", "does", "clause", "4.2.1", "say"}},
+		{"see docs/setup_guide.pdf", []string{"see", "docs/setup_guide.pdf"}},
+		// A leading "-" would negate the term in websearch_to_tsquery
+		{"-excluded --twice", []string{"excluded", "twice"}},
+		{"end of sentence.", []string{"end", "of", "sentence"}},
+		{"보증 기간은?", []string{"보증", "기간은"}},
+		{" ... -- ", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := keywordTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keywordTerms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIdentifierTerms(t *testing.T) {
+	terms := []string{"contract", "AB-1234", "page", "4.2.1", "docs/guide", "2023", "snake_case"}
+	want := []string{"AB-1234", "4.2.1", "docs/guide", "2023"}
+
+	if got := identifierTerms(terms); !reflect.DeepEqual(got, want) {
+		t.Errorf("identifierTerms = %q, want %q", got, want)
+	}
+	if got := identifierTerms([]string{"contract", "page"}); got != nil {
+		t.Errorf("identifierTerms of words = %q, want none", got)
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// collectionCountSelect fills in Collection.DocumentCount
+const collectionCountSelect = `collections.*, (SELECT COUNT(*) FROM collection_documents cd WHERE cd.collection_id = collections.id) AS document_count`
+
+type CollectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+// Create saves the collection together with its initial documents, so a
+// failure leaves neither behind
+func (r *CollectionRepository) Create(ctx context.Context, collection *domain.Collection, documentIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(collection).Error; err != nil {
+			return err
+		}
+		return addDocuments(tx, collection.ID, documentIDs)
+	})
+}
+
+func (r *CollectionRepository) GetByID(ctx context.Context, workspaceID, id string) (*domain.Collection, error) {
+	var collection domain.Collection
+	err := r.db.WithContext(ctx).
+		Scopes(inWorkspace(workspaceID)).
+		Select(collectionCountSelect).
+		Where("id = ?", id).
+		First(&collection).Error
+	return &collection, err
+}
+
+// GetByName returns the workspace's collection with the given name
+func (r *CollectionRepository) GetByName(ctx context.Context, workspaceID, name string) (*domain.Collection, error) {
+	var collection domain.Collection
+	err := r.db.WithContext(ctx).Scopes(inWorkspace(workspaceID)).Where("name = ?", name).First(&collection).Error
+	return &collection, err
+}
+
+// List returns the workspace's collections by name with their document counts
+func (r *CollectionRepository) List(ctx context.Context, workspaceID string) ([]*domain.Collection, error) {
+	var collections []*domain.Collection
+	err := r.db.WithContext(ctx).
+		Scopes(inWorkspace(workspaceID)).
+		Select(collectionCountSelect).
+		Order("name ASC").
+		Find(&collections).Error
+	return collections, err
+}
+
+// CountInWorkspace returns how many of ids are collections of the workspace
+func (r *CollectionRepository) CountInWorkspace(ctx context.Context, workspaceID string, ids []string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Collection{}).
+		Scopes(inWorkspace(workspaceID)).
+		Where("id IN ?", ids).
+		Count(&count).Error
+	return count, err
+}
+
+// Update saves the name and description
+func (r *CollectionRepository) Update(ctx context.Context, collection *domain.Collection) error {
+	return r.db.WithContext(ctx).
+		Model(collection).
+		Select("name", "description").
+		Updates(collection).Error
+}
+
+// Delete removes the collection; its documents are kept
+func (r *CollectionRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&domain.CollectionDocument{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Collection{}, "id = ?", id).Error
+	})
+}
+
+// DocumentIDs returns the documents of the collection, oldest member first
+func (r *CollectionRepository) DocumentIDs(ctx context.Context, id string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.CollectionDocument{}).
+		Where("collection_id = ?", id).
+		Order("added_at ASC").
+		Pluck("document_id", &ids).Error
+	return ids, err
+}
+
+// MemberDocumentIDs returns the distinct documents of the workspace that
+// belong to any of the collections
+func (r *CollectionRepository) MemberDocumentIDs(ctx context.Context, workspaceID string, collectionIDs []string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.CollectionDocument{}).
+		Distinct("collection_documents.document_id").
+		Joins("JOIN collections c ON c.id = collection_documents.collection_id").
+		Where("c.workspace_id = ? AND collection_documents.collection_id IN ?", workspaceID, collectionIDs).
+		Pluck("collection_documents.document_id", &ids).Error
+	return ids, err
+}
+
+// AddDocuments adds the documents to the collection, skipping those already
+// in it
+func (r *CollectionRepository) AddDocuments(ctx context.Context, id string, documentIDs []string) error {
+	return addDocuments(r.db.WithContext(ctx), id, documentIDs)
+}
+
+func addDocuments(db *gorm.DB, id string, documentIDs []string) error {
+	if len(documentIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	members := make([]*domain.CollectionDocument, 0, len(documentIDs))
+	for _, documentID := range documentIDs {
+		members = append(members, &domain.CollectionDocument{
+			CollectionID: id,
+			DocumentID:   documentID,
+			AddedAt:      now,
+		})
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+// RemoveDocuments removes the documents from the collection
+func (r *CollectionRepository) RemoveDocuments(ctx context.Context, id string, documentIDs []string) error {
+	return r.db.WithContext(ctx).
+		Where("collection_id = ? AND document_id IN ?", id, documentIDs).
+		Delete(&domain.CollectionDocument{}).Error
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 
 	"github.com/pdf-rag-system/backend/internal/domain"
 	"gorm.io/gorm"
@@ -19,13 +21,155 @@ func (r *DocumentRepository) Create(ctx context.Context, doc *domain.Document) e
 	return r.db.WithContext(ctx).Create(doc).Error
 }
 
+// GetByID returns a document in any workspace. It is meant for the
+// ingestion workers, whose jobs refer to documents already checked;
+// requests look documents up with GetInWorkspace.
 func (r *DocumentRepository) GetByID(ctx context.Context, id string) (*domain.Document, error) {
 	var doc domain.Document
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&doc).Error
 	return &doc, err
 }
 
-func (r *DocumentRepository) List(ctx context.Context) ([]*domain.Document, error) {
+// GetInWorkspace returns the document only if it belongs to the workspace,
+// so IDs of other workspaces' documents are simply not found
+func (r *DocumentRepository) GetInWorkspace(ctx context.Context, workspaceID, id string) (*domain.Document, error) {
+	var doc domain.Document
+	err := r.db.WithContext(ctx).Scopes(inWorkspace(workspaceID)).Where("id = ?", id).First(&doc).Error
+	return &doc, err
+}
+
+func (r *DocumentRepository) List(ctx context.Context, workspaceID string) ([]*domain.Document, error) {
+	var docs []*domain.Document
+	err := r.db.WithContext(ctx).Scopes(inWorkspace(workspaceID)).Order("upload_time DESC").Find(&docs).Error
+	return docs, err
+}
+
+// DocumentQuery selects documents of a workspace for a listing. Empty fields
+// leave their condition out; dates are YYYY-MM-DD and bound the upload day
+// inclusively.
+type DocumentQuery struct {
+	Status string
+	// Filename matches a case-insensitive substring
+	Filename string
+	// Tags matches documents with any of the tags
+	Tags           []string
+	MinSize        *int64
+	MaxSize        *int64
+	UploadedAfter  string
+	UploadedBefore string
+
+	// Sort is one of the domain.DocumentSort fields, upload time by default.
+	// Ties are broken by ID so pages never overlap.
+	Sort       string
+	Descending bool
+	// After continues the listing past the last document of a previous page
+	After *DocumentCursor
+	Limit int
+}
+
+// DocumentCursor is the position of a document in a listing: the value of
+// its sort field as text and its ID
+type DocumentCursor struct {
+	Value string
+	ID    string
+}
+
+// ListPage returns up to query.Limit documents matching the query, in its
+// sort order
+func (r *DocumentRepository) ListPage(ctx context.Context, workspaceID string, query DocumentQuery) ([]*domain.Document, error) {
+	column, cast := documentSortColumn(query.Sort)
+	direction, compare := "ASC", ">"
+	if query.Descending {
+		direction, compare = "DESC", "<"
+	}
+
+	db := r.db.WithContext(ctx).Scopes(inWorkspace(workspaceID), matchingDocuments(query))
+	if query.After != nil {
+		db = db.Where("("+column+", id) "+compare+" (CAST(? AS "+cast+"), ?)", query.After.Value, query.After.ID)
+	}
+
+	var docs []*domain.Document
+	err := db.Order(column + " " + direction + ", id " + direction).Limit(query.Limit).Find(&docs).Error
+	return docs, err
+}
+
+// Count returns how many documents match the query, ignoring its cursor
+func (r *DocumentRepository) Count(ctx context.Context, workspaceID string, query DocumentQuery) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Document{}).
+		Scopes(inWorkspace(workspaceID), matchingDocuments(query)).
+		Count(&count).Error
+	return count, err
+}
+
+// documentSortColumn returns the column of a sort field and the SQL type its
+// cursor values are cast to
+func documentSortColumn(sort string) (string, string) {
+	switch sort {
+	case domain.DocumentSortFilename:
+		return "filename", "varchar"
+	case domain.DocumentSortFileSize:
+		return "file_size", "bigint"
+	default:
+		return "upload_time", "timestamp"
+	}
+}
+
+// matchingDocuments applies the filters of a document query
+func matchingDocuments(query DocumentQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.Status != "" {
+			db = db.Where("status = ?", query.Status)
+		}
+		if filename := strings.TrimSpace(query.Filename); filename != "" {
+			db = db.Where("filename ILIKE ?", "%"+escapeLike(filename)+"%")
+		}
+		if tags := domain.NormalizeTags(query.Tags); len(tags) > 0 {
+			matches := make([]string, 0, len(tags))
+			args := make([]interface{}, 0, len(tags))
+			for _, tag := range tags {
+				tagJSON, _ := json.Marshal([]string{tag})
+				matches = append(matches, "tags @> ?::jsonb")
+				args = append(args, string(tagJSON))
+			}
+			db = db.Where("("+strings.Join(matches, " OR ")+")", args...)
+		}
+		if query.MinSize != nil {
+			db = db.Where("file_size >= ?", *query.MinSize)
+		}
+		if query.MaxSize != nil {
+			db = db.Where("file_size <= ?", *query.MaxSize)
+		}
+		if query.UploadedAfter != "" {
+			db = db.Where("upload_time >= ?::date", query.UploadedAfter)
+		}
+		if query.UploadedBefore != "" {
+			db = db.Where("upload_time < ?::date + 1", query.UploadedBefore)
+		}
+		return db
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CountInWorkspace returns how many of ids are documents of the workspace
+func (r *DocumentRepository) CountInWorkspace(ctx context.Context, workspaceID string, ids []string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Document{}).
+		Scopes(inWorkspace(workspaceID)).
+		Where("id IN ?", ids).
+		Count(&count).Error
+	return count, err
+}
+
+// ListAll returns the documents of all workspaces, for maintenance that
+// spans them such as building an embedding space
+func (r *DocumentRepository) ListAll(ctx context.Context) ([]*domain.Document, error) {
 	var docs []*domain.Document
 	err := r.db.WithContext(ctx).Order("upload_time DESC").Find(&docs).Error
 	return docs, err
@@ -35,6 +179,62 @@ func (r *DocumentRepository) Update(ctx context.Context, doc *domain.Document) e
 	return r.db.WithContext(ctx).Save(doc).Error
 }
 
+// UpdateFields updates only the given columns, leaving concurrent changes to
+// other columns intact. Aliases of the document are updated too, so they
+// mirror its status and progress.
+func (r *DocumentRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&domain.Document{}).Where("id = ? OR alias_of = ?", id, id).Updates(fields).Error
+}
+
+// UpdateMetadata saves the descriptive fields, tags and metadata of the
+// document alone; unlike UpdateFields its aliases keep their own
+func (r *DocumentRepository) UpdateMetadata(ctx context.Context, doc *domain.Document) error {
+	return r.db.WithContext(ctx).
+		Model(doc).
+		Select("title", "author", "creation_date", "tags", "metadata").
+		Updates(doc).Error
+}
+
+// FindByContentHash returns the oldest non-alias document in the workspace
+// with the given content hash that has not failed. Duplicates are only
+// detected within a workspace, so aliases never share chunks across tenants.
+func (r *DocumentRepository) FindByContentHash(ctx context.Context, workspaceID, hash string) (*domain.Document, error) {
+	var doc domain.Document
+	err := r.db.WithContext(ctx).
+		Scopes(inWorkspace(workspaceID)).
+		Where("content_hash = ? AND alias_of IS NULL AND status <> ?", hash, domain.StatusError).
+		Order("upload_time ASC").
+		First(&doc).Error
+	return &doc, err
+}
+
+// ListAliases returns the aliases of a document, oldest first
+func (r *DocumentRepository) ListAliases(ctx context.Context, id string) ([]*domain.Document, error) {
+	var docs []*domain.Document
+	err := r.db.WithContext(ctx).Where("alias_of = ?", id).Order("upload_time ASC").Find(&docs).Error
+	return docs, err
+}
+
+// DeletePromotingAlias deletes document id and makes its alias aliasID the
+// owner of its chunks, failed chunks and jobs. Remaining aliases are pointed
+// at aliasID.
+func (r *DocumentRepository) DeletePromotingAlias(ctx context.Context, id, aliasID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Document{}).Where("id = ?", aliasID).Update("alias_of", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Document{}).Where("alias_of = ?", id).Update("alias_of", aliasID).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&domain.Chunk{}, &domain.FailedChunk{}, &domain.IngestionJob{}} {
+			if err := tx.Model(model).Where("document_id = ?", id).Update("document_id", aliasID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&domain.Document{}, "id = ?", id).Error
+	})
+}
+
 func (r *DocumentRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&domain.Document{}, "id = ?", id).Error
 }
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmbeddingCacheRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingCacheRepository(db *gorm.DB) *EmbeddingCacheRepository {
+	return &EmbeddingCacheRepository{db: db}
+}
+
+// GetMany returns the cached embeddings of model for the given text hashes,
+// keyed by hash. Hashes without an entry are absent from the map.
+func (r *EmbeddingCacheRepository) GetMany(ctx context.Context, model string, hashes []string) (map[string][]float32, error) {
+	found := make(map[string][]float32, len(hashes))
+	if len(hashes) == 0 {
+		return found, nil
+	}
+
+	var entries []*domain.CachedEmbedding
+	err := r.db.WithContext(ctx).
+		Where("model = ? AND text_hash IN ?", model, hashes).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		found[entry.TextHash] = entry.Embedding.Slice()
+	}
+	return found, nil
+}
+
+// PutMany stores embeddings, keeping existing entries for the same key
+func (r *EmbeddingCacheRepository) PutMany(ctx context.Context, entries []*domain.CachedEmbedding) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(entries, 100).Error
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// maxIndexedDimension is the largest vector dimension HNSW can index
+const maxIndexedDimension = 2000
+
+type EmbeddingSpaceRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingSpaceRepository(db *gorm.DB) *EmbeddingSpaceRepository {
+	return &EmbeddingSpaceRepository{db: db}
+}
+
+// SpaceCoverage counts the documents and chunks stored in a space
+type SpaceCoverage struct {
+	EmbeddingSpaceID string
+	Documents        int64
+	Chunks           int64
+}
+
+func (r *EmbeddingSpaceRepository) Create(ctx context.Context, space *domain.EmbeddingSpace) error {
+	return r.db.WithContext(ctx).Create(space).Error
+}
+
+func (r *EmbeddingSpaceRepository) GetByID(ctx context.Context, id string) (*domain.EmbeddingSpace, error) {
+	var space domain.EmbeddingSpace
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&space).Error
+	return &space, err
+}
+
+// GetActive returns the space queries are answered from
+func (r *EmbeddingSpaceRepository) GetActive(ctx context.Context) (*domain.EmbeddingSpace, error) {
+	var space domain.EmbeddingSpace
+	err := r.db.WithContext(ctx).Where("status = ?", domain.SpaceStatusActive).First(&space).Error
+	return &space, err
+}
+
+func (r *EmbeddingSpaceRepository) List(ctx context.Context) ([]*domain.EmbeddingSpace, error) {
+	var spaces []*domain.EmbeddingSpace
+	err := r.db.WithContext(ctx).Order("created_at ASC").Find(&spaces).Error
+	return spaces, err
+}
+
+func (r *EmbeddingSpaceRepository) ListByStatus(ctx context.Context, status string) ([]*domain.EmbeddingSpace, error) {
+	var spaces []*domain.EmbeddingSpace
+	err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at ASC").Find(&spaces).Error
+	return spaces, err
+}
+
+// Coverage returns how many documents and chunks each space holds, keyed by
+// space ID
+func (r *EmbeddingSpaceRepository) Coverage(ctx context.Context) (map[string]*SpaceCoverage, error) {
+	var rows []*SpaceCoverage
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT embedding_space_id, COUNT(DISTINCT document_id) AS documents, COUNT(*) AS chunks
+		FROM chunks
+		WHERE embedding_space_id IS NOT NULL
+		GROUP BY embedding_space_id
+	`).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := make(map[string]*SpaceCoverage, len(rows))
+	for _, row := range rows {
+		coverage[row.EmbeddingSpaceID] = row
+	}
+	return coverage, nil
+}
+
+// CountMissingDocuments counts indexed documents that have no chunks in the
+// space yet
+func (r *EmbeddingSpaceRepository) CountMissingDocuments(ctx context.Context, id string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*)
+		FROM documents d
+		WHERE d.alias_of IS NULL
+		  AND d.status IN (?, ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM chunks c
+			WHERE c.document_id = d.id AND c.embedding_space_id = ?
+		  )
+	`, domain.StatusCompleted, domain.StatusPartial, id).Scan(&count).Error
+	return count, err
+}
+
+// Activate makes the space the one queries use and retires the previously
+// active space
+func (r *EmbeddingSpaceRepository) Activate(ctx context.Context, space *domain.EmbeddingSpace) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.EmbeddingSpace{}).
+			Where("status = ? AND id <> ?", domain.SpaceStatusActive, space.ID).
+			Update("status", domain.SpaceStatusRetired).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.EmbeddingSpace{}).
+			Where("id = ?", space.ID).
+			Update("status", domain.SpaceStatusActive).Error; err != nil {
+			return err
+		}
+		// Documents are now served by the space's model
+		return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+			Model(&domain.Document{}).
+			Update("embedding_model", space.Model).Error
+	})
+}
+
+// AssignUnassigned moves chunks stored before embedding spaces existed into
+// the space
+func (r *EmbeddingSpaceRepository) AssignUnassigned(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&domain.Chunk{}, &domain.FailedChunk{}} {
+			if err := tx.Model(model).
+				Where("embedding_space_id IS NULL OR embedding_space_id = ''").
+				Update("embedding_space_id", id).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the space with its chunks and failed chunks
+func (r *EmbeddingSpaceRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("embedding_space_id = ?", id).Delete(&domain.Chunk{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("embedding_space_id = ?", id).Delete(&domain.FailedChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.EmbeddingSpace{}, "id = ?", id).Error
+	})
+}
+
+// EnsureIndex creates the space's partial HNSW index on the chunk embeddings,
+// cast to the space's dimension. It is built concurrently so searches and
+// ingestion keep running. Spaces too wide for HNSW are searched exactly.
+func (r *EmbeddingSpaceRepository) EnsureIndex(ctx context.Context, space *domain.EmbeddingSpace) error {
+	if space.Dimension > maxIndexedDimension {
+		fmt.Printf("WARNING: Embedding space %s has %d dimensions; HNSW supports at most %d, so searches will be exact\n",
+			space.ID, space.Dimension, maxIndexedDimension)
+		return nil
+	}
+
+	// Identifiers and the predicate cannot be bound as parameters. The ID is
+	// generated by us and the dimension is an integer.
+	return r.db.WithContext(ctx).Exec(fmt.Sprintf(
+		`CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON chunks USING hnsw ((embedding::vector(%d)) vector_cosine_ops) WHERE embedding_space_id = '%s'`,
+		space.IndexName(), space.Dimension, space.ID,
+	)).Error
+}
+
+// DropIndex removes the space's HNSW index
+func (r *EmbeddingSpaceRepository) DropIndex(ctx context.Context, space *domain.EmbeddingSpace) error {
+	return r.db.WithContext(ctx).Exec(fmt.Sprintf(`DROP INDEX CONCURRENTLY IF EXISTS %s`, space.IndexName())).Error
+}
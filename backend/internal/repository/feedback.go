@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FeedbackRepository struct {
+	db *gorm.DB
+}
+
+func NewFeedbackRepository(db *gorm.DB) *FeedbackRepository {
+	return &FeedbackRepository{db: db}
+}
+
+// Upsert stores feedback, replacing any earlier feedback on the same query
+func (r *FeedbackRepository) Upsert(ctx context.Context, feedback *domain.QueryFeedback) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "query_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rating", "comment", "citations"}),
+		}).
+		Create(feedback).Error
+}
+
+func (r *FeedbackRepository) GetByQueryID(ctx context.Context, queryID int64) (*domain.QueryFeedback, error) {
+	var feedback domain.QueryFeedback
+	err := r.db.WithContext(ctx).Where("query_id = ?", queryID).First(&feedback).Error
+	return &feedback, err
+}
+
+// EachLabeledCitation calls fn for every citation marked relevant or not in
+// feedback on the workspace's queries given since the given time, oldest first. Chunk content and
+// scores come from the recorded query, so they reflect what was retrieved
+// even if the document has since been reindexed.
+func (r *FeedbackRepository) EachLabeledCitation(ctx context.Context, workspaceID string, since time.Time, fn func(*domain.LabeledCitation) error) error {
+	rows, err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			q.id AS query_id,
+			q.query,
+			m->>'chunk_id' AS chunk_id,
+			COALESCE(c->>'document_id', '') AS document_id,
+			COALESCE((c->>'page_number')::int, 0) AS page_number,
+			COALESCE(c->>'content', '') AS content,
+			(m->>'relevant')::boolean AS relevant,
+			COALESCE(f.rating, '') AS rating,
+			COALESCE(q.search_mode, '') AS search_mode,
+			COALESCE((s->>'score')::float8, 0) AS score,
+			COALESCE((s->>'vector_score')::float8, 0) AS vector_score,
+			COALESCE((s->>'keyword_score')::float8, 0) AS keyword_score,
+			COALESCE((s->>'rerank_score')::float8, 0) AS rerank_score,
+			f.updated_at AS created_at
+		FROM query_feedback f
+		JOIN query_history q ON q.id = f.query_id
+		CROSS JOIN jsonb_array_elements(f.citations) m
+		LEFT JOIN LATERAL (
+			SELECT e FROM jsonb_array_elements(q.citations) e
+			WHERE e->>'chunk_id' = m->>'chunk_id' LIMIT 1
+		) cited(c) ON true
+		LEFT JOIN LATERAL (
+			SELECT e FROM jsonb_array_elements(q.scores) e
+			WHERE e->>'chunk_id' = m->>'chunk_id' LIMIT 1
+		) scored(s) ON true
+		WHERE q.workspace_id = ? AND f.updated_at >= ?
+		ORDER BY f.updated_at, q.id
+	`, workspaceID, since).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var labeled domain.LabeledCitation
+		if err := r.db.ScanRows(rows, &labeled); err != nil {
+			return err
+		}
+		if err := fn(&labeled); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
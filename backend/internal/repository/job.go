@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) Create(ctx context.Context, job *domain.IngestionJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// ClaimNext marks the oldest due pending job as running and returns it, or
+// nil when there is nothing to do. Jobs locked by another worker are skipped.
+func (r *JobRepository) ClaimNext(ctx context.Context) (*domain.IngestionJob, error) {
+	var job domain.IngestionJob
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", domain.JobStatusPending, now).
+			Order("run_at ASC").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		job.Status = domain.JobStatusRunning
+		job.Attempts++
+		job.StartedAt = &now
+		job.HeartbeatAt = &now
+		return tx.Save(&job).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// Heartbeat records that the worker running the job is still alive
+func (r *JobRepository) Heartbeat(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&domain.IngestionJob{}).
+		Where("id = ? AND status = ?", id, domain.JobStatusRunning).
+		Update("heartbeat_at", time.Now()).Error
+}
+
+func (r *JobRepository) MarkSucceeded(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&domain.IngestionJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      domain.JobStatusSucceeded,
+			"last_error":  "",
+			"finished_at": time.Now(),
+		}).Error
+}
+
+func (r *JobRepository) MarkFailed(ctx context.Context, id, lastError string) error {
+	return r.db.WithContext(ctx).Model(&domain.IngestionJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      domain.JobStatusFailed,
+			"last_error":  lastError,
+			"finished_at": time.Now(),
+		}).Error
+}
+
+// Reschedule puts a failed attempt back in the queue to run again at runAt
+func (r *JobRepository) Reschedule(ctx context.Context, id, lastError string, runAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.IngestionJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       domain.JobStatusPending,
+			"last_error":   lastError,
+			"run_at":       runAt,
+			"heartbeat_at": nil,
+		}).Error
+}
+
+// RequeueStale returns running jobs whose worker stopped sending heartbeats
+// before cutoff (for example because the server restarted) to the queue.
+func (r *JobRepository) RequeueStale(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.IngestionJob{}).
+		Where("status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", domain.JobStatusRunning, cutoff).
+		Updates(map[string]interface{}{
+			"status":       domain.JobStatusPending,
+			"last_error":   "worker stopped responding",
+			"run_at":       time.Now(),
+			"heartbeat_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListOrphanedDocumentIDs returns documents still marked as processing that
+// have no pending or running job, such as uploads made before the job queue.
+// Aliases are skipped; they follow the document they duplicate.
+func (r *JobRepository) ListOrphanedDocumentIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT d.id
+		FROM documents d
+		WHERE d.status = 'processing'
+		  AND d.alias_of IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM ingestion_jobs j
+			WHERE j.document_id = d.id AND j.status IN (?, ?)
+		  )
+	`, domain.JobStatusPending, domain.JobStatusRunning).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *JobRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	return r.db.WithContext(ctx).Where("document_id = ?", documentID).Delete(&domain.IngestionJob{}).Error
+}
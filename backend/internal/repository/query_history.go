@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type QueryHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewQueryHistoryRepository(db *gorm.DB) *QueryHistoryRepository {
+	return &QueryHistoryRepository{db: db}
+}
+
+// QueryCount is how often a query was asked; queries are grouped
+// case-insensitively
+type QueryCount struct {
+	Query     string    `json:"query"`
+	Count     int64     `json:"count"`
+	LastAsked time.Time `json:"last_asked"`
+}
+
+// LatencyPercentiles are the p50/p95 latencies in milliseconds, overall and
+// per stage
+type LatencyPercentiles struct {
+	Queries      int64   `json:"queries"`
+	TotalP50     float64 `json:"total_p50_ms"`
+	TotalP95     float64 `json:"total_p95_ms"`
+	EmbedP50     float64 `json:"embed_p50_ms"`
+	EmbedP95     float64 `json:"embed_p95_ms"`
+	SearchP50    float64 `json:"search_p50_ms"`
+	SearchP95    float64 `json:"search_p95_ms"`
+	LLMP50       float64 `json:"llm_p50_ms" gorm:"column:llm_p50"`
+	LLMP95       float64 `json:"llm_p95_ms" gorm:"column:llm_p95"`
+	AvgTokens    float64 `json:"avg_total_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	FailedCount  int64   `json:"failed"`
+	ZeroResults  int64   `json:"zero_results"`
+	AvgCitations float64 `json:"avg_citations"`
+}
+
+// DocumentCitations is how often a document was cited
+type DocumentCitations struct {
+	DocumentID string `json:"document_id"`
+	Filename   string `json:"filename"`
+	Citations  int64  `json:"citations"`
+	Queries    int64  `json:"queries"`
+}
+
+func (r *QueryHistoryRepository) Create(ctx context.Context, entry *domain.QueryHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *QueryHistoryRepository) GetByID(ctx context.Context, workspaceID string, id int64) (*domain.QueryHistory, error) {
+	var entry domain.QueryHistory
+	err := r.db.WithContext(ctx).Scopes(inWorkspace(workspaceID)).Where("id = ?", id).First(&entry).Error
+	return &entry, err
+}
+
+// TopQueries returns the most frequently asked queries since the given time
+func (r *QueryHistoryRepository) TopQueries(ctx context.Context, workspaceID string, since time.Time, limit int) ([]*QueryCount, error) {
+	return r.countQueries(ctx, r.db.Scopes(inWorkspace(workspaceID)).Where("created_at >= ?", since), limit)
+}
+
+// ZeroResultQueries returns the most frequent successful queries that found
+// nothing relevant to cite
+func (r *QueryHistoryRepository) ZeroResultQueries(ctx context.Context, workspaceID string, since time.Time, limit int) ([]*QueryCount, error) {
+	return r.countQueries(ctx, r.db.Scopes(inWorkspace(workspaceID)).Where("created_at >= ? AND result_count = 0 AND COALESCE(error, '') = ''", since), limit)
+}
+
+func (r *QueryHistoryRepository) countQueries(ctx context.Context, scope *gorm.DB, limit int) ([]*QueryCount, error) {
+	var counts []*QueryCount
+	err := scope.WithContext(ctx).
+		Model(&domain.QueryHistory{}).
+		Select("MIN(query) AS query, COUNT(*) AS count, MAX(created_at) AS last_asked").
+		Group("LOWER(TRIM(query))").
+		Order("count DESC, last_asked DESC").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// Latency returns latency percentiles and totals of queries since the given
+// time. Failed queries are counted but excluded from the percentiles.
+func (r *QueryHistoryRepository) Latency(ctx context.Context, workspaceID string, since time.Time) (*LatencyPercentiles, error) {
+	var latency LatencyPercentiles
+	err := r.db.WithContext(ctx).Raw(`
+		WITH q AS (
+			SELECT *, COALESCE(error, '') <> '' AS failed
+			FROM query_history
+			WHERE workspace_id = ? AND created_at >= ?
+		)
+		SELECT
+			COUNT(*) AS queries,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE NOT failed), 0) AS total_p50,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE NOT failed), 0) AS total_p95,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY embed_ms) FILTER (WHERE NOT failed), 0) AS embed_p50,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY embed_ms) FILTER (WHERE NOT failed), 0) AS embed_p95,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY search_ms) FILTER (WHERE NOT failed), 0) AS search_p50,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY search_ms) FILTER (WHERE NOT failed), 0) AS search_p95,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY llm_ms) FILTER (WHERE NOT failed AND result_count > 0), 0) AS llm_p50,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY llm_ms) FILTER (WHERE NOT failed AND result_count > 0), 0) AS llm_p95,
+			COALESCE(AVG(total_tokens), 0) AS avg_tokens,
+			COALESCE(SUM(total_tokens), 0) AS total_tokens,
+			COUNT(*) FILTER (WHERE failed) AS failed_count,
+			COUNT(*) FILTER (WHERE NOT failed AND result_count = 0) AS zero_results,
+			COALESCE(AVG(CASE WHEN uncited THEN 0 ELSE result_count END) FILTER (WHERE NOT failed), 0) AS avg_citations
+		FROM q
+	`, workspaceID, since).Scan(&latency).Error
+	if err != nil {
+		return nil, err
+	}
+	return &latency, nil
+}
+
+// TopDocuments returns the documents cited most often since the given time.
+// The sources of uncited answers are not counted.
+func (r *QueryHistoryRepository) TopDocuments(ctx context.Context, workspaceID string, since time.Time, limit int) ([]*DocumentCitations, error) {
+	var docs []*DocumentCitations
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			c->>'document_id' AS document_id,
+			MAX(c->>'filename') AS filename,
+			COUNT(*) AS citations,
+			COUNT(DISTINCT q.id) AS queries
+		FROM query_history q, jsonb_array_elements(q.citations) c
+		WHERE q.workspace_id = ? AND q.created_at >= ? AND NOT q.uncited
+		GROUP BY c->>'document_id'
+		ORDER BY citations DESC, queries DESC
+		LIMIT ?
+	`, workspaceID, since, limit).Scan(&docs).Error
+	return docs, err
+}
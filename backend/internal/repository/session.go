@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetByID returns the session of the workspace with its turns in order
+func (r *SessionRepository) GetByID(ctx context.Context, workspaceID, id string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.db.WithContext(ctx).
+		Scopes(inWorkspace(workspaceID)).
+		Preload("Turns", func(db *gorm.DB) *gorm.DB {
+			return db.Order("turn_index ASC")
+		}).
+		Where("id = ?", id).
+		First(&session).Error
+	return &session, err
+}
+
+func (r *SessionRepository) List(ctx context.Context, workspaceID string) ([]*domain.Session, error) {
+	var sessions []*domain.Session
+	err := r.db.WithContext(ctx).Scopes(inWorkspace(workspaceID)).Order("updated_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// RecentTurns returns up to limit most recent turns, oldest first
+func (r *SessionRepository) RecentTurns(ctx context.Context, sessionID string, limit int) ([]*domain.SessionTurn, error) {
+	var turns []*domain.SessionTurn
+	err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("turn_index DESC").
+		Limit(limit).
+		Find(&turns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(turns)-1; i < j; i, j = i+1, j-1 {
+		turns[i], turns[j] = turns[j], turns[i]
+	}
+	return turns, nil
+}
+
+// AppendTurn stores turn as the next turn of session, creating the session
+// first if it is new. A session is thus only stored along with its first
+// turn, never empty.
+func (r *SessionRepository) AppendTurn(ctx context.Context, session *domain.Session, turn *domain.SessionTurn) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(session).Error; err != nil {
+			return err
+		}
+
+		// Lock the session row so concurrent turns get distinct indexes
+		var locked domain.Session
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", turn.SessionID).
+			First(&locked).Error; err != nil {
+			return err
+		}
+
+		var next int
+		if err := tx.Model(&domain.SessionTurn{}).
+			Where("session_id = ?", turn.SessionID).
+			Select("COALESCE(MAX(turn_index) + 1, 0)").
+			Scan(&next).Error; err != nil {
+			return err
+		}
+		turn.TurnIndex = next
+
+		if err := tx.Create(turn).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.Session{}).
+			Where("id = ?", turn.SessionID).
+			Update("updated_at", time.Now()).Error
+	})
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", id).Delete(&domain.SessionTurn{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Session{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// inWorkspace limits a query to the rows of one workspace. Every query on
+// tenant data that is driven by a request goes through it.
+func inWorkspace(workspaceID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("workspace_id = ?", workspaceID)
+	}
+}
+
+type WorkspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+func (r *WorkspaceRepository) Create(ctx context.Context, workspace *domain.Workspace) error {
+	return r.db.WithContext(ctx).Create(workspace).Error
+}
+
+func (r *WorkspaceRepository) GetByID(ctx context.Context, id string) (*domain.Workspace, error) {
+	var workspace domain.Workspace
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&workspace).Error
+	return &workspace, err
+}
+
+func (r *WorkspaceRepository) List(ctx context.Context) ([]*domain.Workspace, error) {
+	var workspaces []*domain.Workspace
+	err := r.db.WithContext(ctx).Order("created_at ASC").Find(&workspaces).Error
+	return workspaces, err
+}
+
+// Update saves the name and quotas
+func (r *WorkspaceRepository) Update(ctx context.Context, workspace *domain.Workspace) error {
+	return r.db.WithContext(ctx).
+		Model(workspace).
+		Select("name", "max_documents", "max_storage_bytes").
+		Updates(workspace).Error
+}
+
+// Delete removes the workspace with its sessions, recorded queries,
+// collections and API keys. The caller must make sure it holds no documents.
+func (r *WorkspaceRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DELETE FROM session_turns WHERE session_id IN (SELECT id FROM sessions WHERE workspace_id = ?)`, id).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&domain.Session{}, &domain.QueryHistory{}, &domain.Collection{}, &domain.APIKey{}} {
+			if err := tx.Scopes(inWorkspace(id)).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&domain.Workspace{}, "id = ?", id).Error
+	})
+}
+
+// Usage returns what the workspace stores
+func (r *WorkspaceRepository) Usage(ctx context.Context, id string) (*domain.WorkspaceUsage, error) {
+	return usage(r.db.WithContext(ctx), id)
+}
+
+func usage(db *gorm.DB, workspaceID string) (*domain.WorkspaceUsage, error) {
+	var u domain.WorkspaceUsage
+	err := db.Model(&domain.Document{}).
+		Scopes(inWorkspace(workspaceID)).
+		Select("COUNT(*) AS documents, COALESCE(SUM(file_size) FILTER (WHERE alias_of IS NULL), 0) AS storage_bytes").
+		Scan(&u).Error
+	return &u, err
+}
+
+// CreateDocument stores doc in its workspace if check accepts the
+// workspace's current usage. The workspace row is locked meanwhile, so
+// concurrent uploads cannot overrun a quota together.
+func (r *WorkspaceRepository) CreateDocument(ctx context.Context, doc *domain.Document, check func(*domain.Workspace, *domain.WorkspaceUsage) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var workspace domain.Workspace
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", doc.WorkspaceID).
+			First(&workspace).Error; err != nil {
+			return err
+		}
+
+		u, err := usage(tx, doc.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if err := check(&workspace, u); err != nil {
+			return err
+		}
+
+		return tx.Create(doc).Error
+	})
+}
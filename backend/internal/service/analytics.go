@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/repository"
+)
+
+const (
+	defaultAnalyticsDays  = 30
+	defaultAnalyticsLimit = 20
+	maxAnalyticsLimit     = 100
+)
+
+// AnalyticsService reports on the queries recorded in query_history
+type AnalyticsService struct {
+	historyRepo *repository.QueryHistoryRepository
+}
+
+func NewAnalyticsService(historyRepo *repository.QueryHistoryRepository) *AnalyticsService {
+	return &AnalyticsService{historyRepo: historyRepo}
+}
+
+// AnalyticsWindow selects the queries an analytics report covers
+type AnalyticsWindow struct {
+	// Days is how far back to look; 0 uses the default of 30
+	Days int
+	// Limit caps the number of rows in ranked reports; 0 uses the default of 20
+	Limit int
+}
+
+func (w AnalyticsWindow) since() time.Time {
+	days := w.Days
+	if days <= 0 {
+		days = defaultAnalyticsDays
+	}
+	return time.Now().AddDate(0, 0, -days)
+}
+
+func (w AnalyticsWindow) limit() int {
+	if w.Limit <= 0 {
+		return defaultAnalyticsLimit
+	}
+	if w.Limit > maxAnalyticsLimit {
+		return maxAnalyticsLimit
+	}
+	return w.Limit
+}
+
+// TopQueries returns the most frequently asked queries
+func (s *AnalyticsService) TopQueries(ctx context.Context, workspaceID string, window AnalyticsWindow) ([]*repository.QueryCount, error) {
+	return s.historyRepo.TopQueries(ctx, workspaceID, window.since(), window.limit())
+}
+
+// ZeroResultQueries returns the most frequent queries nothing relevant was
+// found for, i.e. gaps in the indexed documents
+func (s *AnalyticsService) ZeroResultQueries(ctx context.Context, workspaceID string, window AnalyticsWindow) ([]*repository.QueryCount, error) {
+	return s.historyRepo.ZeroResultQueries(ctx, workspaceID, window.since(), window.limit())
+}
+
+// Latency returns p50/p95 latency overall and per stage
+func (s *AnalyticsService) Latency(ctx context.Context, workspaceID string, window AnalyticsWindow) (*repository.LatencyPercentiles, error) {
+	return s.historyRepo.Latency(ctx, workspaceID, window.since())
+}
+
+// TopDocuments returns the documents cited most often
+func (s *AnalyticsService) TopDocuments(ctx context.Context, workspaceID string, window AnalyticsWindow) ([]*repository.DocumentCitations, error) {
+	return s.historyRepo.TopDocuments(ctx, workspaceID, window.since(), window.limit())
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAPIKeyNotFound is returned when no key has the given ID
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey is returned for a missing, unknown or revoked key
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrInvalidAPIKeyRequest is returned for a key that cannot be created
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+)
+
+const (
+	// apiKeyPrefix starts every key, so leaked keys are easy to search for
+	apiKeyPrefix = "prk_"
+	// apiKeyDisplayLength is how much of a key is kept to show in listings
+	apiKeyDisplayLength = 12
+	// lastUsedInterval limits how often a key's last_used_at is written
+	lastUsedInterval = time.Minute
+)
+
+// APIKeyService issues, revokes and checks API keys
+type APIKeyService struct {
+	repo       *repository.APIKeyRepository
+	workspaces *WorkspaceService
+
+	mu       sync.Mutex
+	lastUsed map[string]time.Time
+}
+
+func NewAPIKeyService(repo *repository.APIKeyRepository, workspaces *WorkspaceService) *APIKeyService {
+	return &APIKeyService{
+		repo:       repo,
+		workspaces: workspaces,
+		lastUsed:   make(map[string]time.Time),
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+	// QueryRateLimit and UploadRateLimit override the configured requests
+	// per minute for this key
+	QueryRateLimit  *int `json:"query_rate_limit"`
+	UploadRateLimit *int `json:"upload_rate_limit"`
+	// WorkspaceID binds the key to one workspace; without it the key may
+	// act in any workspace
+	WorkspaceID *string `json:"workspace_id"`
+}
+
+// CreatedAPIKey is a new key along with its secret, which is not stored and
+// cannot be retrieved later
+type CreatedAPIKey struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// Create issues a new key
+func (s *APIKeyService) Create(ctx context.Context, req CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	scope := strings.ToLower(strings.TrimSpace(req.Scope))
+	if !domain.ValidScope(scope) {
+		return nil, fmt.Errorf("%w: scope must be read, write or admin", ErrInvalidAPIKeyRequest)
+	}
+	for _, limit := range []*int{req.QueryRateLimit, req.UploadRateLimit} {
+		if limit != nil && *limit < 0 {
+			return nil, fmt.Errorf("%w: rate limits must not be negative", ErrInvalidAPIKeyRequest)
+		}
+	}
+	if req.WorkspaceID != nil {
+		exists, err := s.workspaces.Exists(ctx, *req.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: workspace %s does not exist", ErrInvalidAPIKeyRequest, *req.WorkspaceID)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &domain.APIKey{
+		ID:              uuid.New().String(),
+		Name:            name,
+		Prefix:          key[:apiKeyDisplayLength],
+		KeyHash:         hashAPIKey(key),
+		Scope:           scope,
+		QueryRateLimit:  req.QueryRateLimit,
+		UploadRateLimit: req.UploadRateLimit,
+		WorkspaceID:     req.WorkspaceID,
+	}
+	if err := s.repo.Create(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// List returns the keys bound to workspaceID, or all keys when it is nil
+func (s *APIKeyService) List(ctx context.Context, workspaceID *string) ([]*domain.APIKey, error) {
+	if workspaceID != nil {
+		return s.repo.ListInWorkspace(ctx, *workspaceID)
+	}
+	return s.repo.List(ctx)
+}
+
+// Revoke disables a key; requests made with it are rejected from then on.
+// With a workspaceID only keys bound to that workspace can be revoked.
+func (s *APIKeyService) Revoke(ctx context.Context, workspaceID *string, id string) error {
+	apiKey, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get api key: %w", err)
+	}
+	if workspaceID != nil && (apiKey.WorkspaceID == nil || *apiKey.WorkspaceID != *workspaceID) {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := s.repo.Revoke(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+// Authenticate returns the active key matching key. The key is looked up on
+// every call so that revocation takes effect immediately.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.repo.GetByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if apiKey.Revoked() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	s.touch(ctx, apiKey.ID)
+	return apiKey, nil
+}
+
+// touch records that a key was used, at most once per lastUsedInterval
+func (s *APIKeyService) touch(ctx context.Context, id string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	if now.Sub(s.lastUsed[id]) < lastUsedInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastUsed[id] = now
+	s.mu.Unlock()
+
+	if err := s.repo.TouchLastUsed(ctx, id, now); err != nil {
+		fmt.Printf("WARNING: Failed to record use of api key %s: %v\n", id, err)
+	}
+}
+
+// hashAPIKey is the stored form of a key. Keys are long random strings, so
+// a fast unsalted hash is enough and allows lookup by hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pdf-rag-system/backend/internal/client"
 	"github.com/pdf-rag-system/backend/internal/domain"
@@ -12,68 +13,331 @@ import (
 )
 
 type ChatService struct {
-	chunkRepo *repository.ChunkRepository
-	llmClient *client.LLMClient
-	config    *config.Config
+	chunkRepo      *repository.ChunkRepository
+	spaceRepo      *repository.EmbeddingSpaceRepository
+	historyRepo    *repository.QueryHistoryRepository
+	sessionService *SessionService
+	collections    *CollectionService
+	embeddings     *EmbeddingService
+	reranker       Reranker
+	grounding      GroundednessChecker
+	llm            client.ChatProvider
+	config         *config.Config
 }
 
-func NewChatService(chunkRepo *repository.ChunkRepository, cfg *config.Config) *ChatService {
-	llmClient := client.NewLLMClient(cfg.LLM.APIBaseURL, cfg.LLM.APIKey, cfg.LLM.Model)
-
+func NewChatService(
+	chunkRepo *repository.ChunkRepository,
+	spaceRepo *repository.EmbeddingSpaceRepository,
+	historyRepo *repository.QueryHistoryRepository,
+	sessionService *SessionService,
+	collections *CollectionService,
+	embeddings *EmbeddingService,
+	llm client.ChatProvider,
+	reranker Reranker,
+	grounding GroundednessChecker,
+	cfg *config.Config,
+) *ChatService {
 	return &ChatService{
-		chunkRepo: chunkRepo,
-		llmClient: llmClient,
-		config:    cfg,
+		chunkRepo:      chunkRepo,
+		spaceRepo:      spaceRepo,
+		historyRepo:    historyRepo,
+		sessionService: sessionService,
+		collections:    collections,
+		embeddings:     embeddings,
+		reranker:       reranker,
+		grounding:      grounding,
+		llm:            llm,
+		config:         cfg,
 	}
 }
 
 type QueryRequest struct {
+	// WorkspaceID is the workspace searched; it is set from the request's
+	// API key or workspace header, never from the body
+	WorkspaceID string   `json:"-"`
 	Query       string   `json:"query"`
 	DocumentIDs []string `json:"document_ids"`
+	// CollectionIDs adds the documents of these collections to DocumentIDs
+	CollectionIDs []string `json:"collection_ids"`
+	// AllDocuments searches every document of the workspace instead of
+	// DocumentIDs and CollectionIDs
+	AllDocuments bool `json:"all_documents"`
+	// Filters narrows the searched documents by tags, author, creation date
+	// and metadata
+	Filters *domain.DocumentFilter `json:"filters"`
+	// SessionID continues an existing conversation; empty starts a new one
+	SessionID string `json:"session_id"`
+	// SearchMode is "vector", "keyword" or "hybrid"; empty uses the configured default
+	SearchMode string `json:"search_mode"`
+	// VectorWeight is the weight of vector vs keyword ranks in hybrid mode (0-1)
+	VectorWeight *float64 `json:"vector_weight"`
 }
 
 type QueryResponse struct {
-	Answer    string                   `json:"answer"`
+	// Answer has its source markers normalized to [Source N], where N is the
+	// position of the cited chunk in Citations
+	Answer string `json:"answer"`
+	// Citations are the chunks the answer cites (all retrieved chunks if the
+	// answer has no markers, see Uncited)
 	Citations []*domain.SearchResult `json:"citations"`
+	// SessionID is empty when a new session could not be stored
+	SessionID string `json:"session_id"`
+	// SessionSaved reports whether the turn was recorded in the session
+	SessionSaved   bool   `json:"session_saved"`
+	RewrittenQuery string `json:"rewritten_query,omitempty"`
+	// QueryID identifies the recorded query, for attaching feedback
+	QueryID int64 `json:"query_id,omitempty"`
+	// Markers maps each marker in Answer to its chunk and the text it supports
+	Markers []CitationMarker `json:"markers"`
+	// InvalidSources are source numbers the model cited that do not exist;
+	// their markers were removed from Answer
+	InvalidSources []int `json:"invalid_sources,omitempty"`
+	// Uncited is set when the answer cites no sources
+	Uncited bool `json:"uncited,omitempty"`
+	// Groundedness is the verdict of the groundedness check; nil when
+	// checking is disabled, failed or there was nothing to check
+	Groundedness *Groundedness `json:"groundedness,omitempty"`
 }
 
-func (s *ChatService) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+func (s *ChatService) Query(ctx context.Context, req *QueryRequest) (resp *QueryResponse, err error) {
 	fmt.Printf("\n=== QUERY START ===\n")
 	fmt.Printf("Query: %s\n", req.Query)
 	fmt.Printf("Document IDs: %v\n", req.DocumentIDs)
 
-	// Generate query embedding
-	embeddingClient := client.NewLLMClient(s.config.Embedding.APIBaseURL, s.config.Embedding.APIKey, s.config.Embedding.Model)
-	queryEmbedding, err := embeddingClient.GetEmbedding(req.Query, s.config.Embedding.Model)
+	trace := newQueryTrace()
+	defer func() { s.recordQuery(req, trace, resp, err) }()
+
+	if err := s.resolveDocuments(ctx, req); err != nil {
+		return nil, err
+	}
+	session, question, err := s.prepareQuestion(ctx, req, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	searchResults, fallback, err := s.retrieve(ctx, question, req, trace)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to generate query embedding: %v\n", err)
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+		return nil, err
+	}
+	if fallback != nil {
+		return s.finish(ctx, session, req, question, fallback), nil
 	}
-	fmt.Printf("Query embedding generated (dim: %d, first 5 values: [%.4f, %.4f, %.4f, %.4f, %.4f])\n",
-		len(queryEmbedding), queryEmbedding[0], queryEmbedding[1], queryEmbedding[2], queryEmbedding[3], queryEmbedding[4])
 
-	// Vector search - get more results for better coverage
-	fmt.Printf("Performing vector search (top 10 results)...\n")
-	searchResults, err := s.chunkRepo.VectorSearch(ctx, queryEmbedding, req.DocumentIDs, 10)
+	// Call LLM
+	messages := buildMessages(question, searchResults)
+
+	fmt.Println("Calling LLM for answer generation...")
+	llmStart := time.Now()
+	answer, usage, err := s.llm.Chat(ctx, messages)
+	trace.llm = time.Since(llmStart)
+	trace.usage.Add(usage)
 	if err != nil {
-		fmt.Printf("ERROR: Vector search failed: %v\n", err)
-		return nil, fmt.Errorf("vector search failed: %w", err)
+		fmt.Printf("ERROR: LLM call failed: %v\n", err)
+		return nil, fmt.Errorf("LLM call failed: %w", err)
+	}
+
+	fmt.Printf("Answer generated (length: %d chars)\n", len(answer))
+	resp = citedResponse(answer, searchResults)
+	s.checkGroundedness(ctx, resp)
+
+	fmt.Printf("=== QUERY COMPLETE ===\n\n")
+
+	return s.finish(ctx, session, req, question, resp), nil
+}
+
+// StreamCallbacks receives the stages of a streamed query as they happen.
+// Returning an error from either callback aborts the query.
+type StreamCallbacks struct {
+	OnCitations func(citations []*domain.SearchResult) error
+	OnToken     func(token string) error
+}
+
+// QueryStream runs the same retrieval as Query, reports the citations first
+// and then forwards the answer token by token as the LLM produces it. The
+// streamed tokens number sources as the reported citations do; the returned
+// response has the normalized answer and the narrowed citations, and in
+// reject mode replaces an answer that failed the groundedness check.
+func (s *ChatService) QueryStream(ctx context.Context, req *QueryRequest, cb StreamCallbacks) (resp *QueryResponse, err error) {
+	fmt.Printf("\n=== STREAM QUERY START ===\n")
+	fmt.Printf("Query: %s\n", req.Query)
+	fmt.Printf("Document IDs: %v\n", req.DocumentIDs)
+
+	trace := newQueryTrace()
+	defer func() { s.recordQuery(req, trace, resp, err) }()
+
+	if err := s.resolveDocuments(ctx, req); err != nil {
+		return nil, err
+	}
+	session, question, err := s.prepareQuestion(ctx, req, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	searchResults, fallback, err := s.retrieve(ctx, question, req, trace)
+	if err != nil {
+		return nil, err
+	}
+	if fallback != nil {
+		if err := cb.OnCitations(fallback.Citations); err != nil {
+			return nil, err
+		}
+		if err := cb.OnToken(fallback.Answer); err != nil {
+			return nil, err
+		}
+		return s.finish(ctx, session, req, question, fallback), nil
+	}
+
+	if err := cb.OnCitations(searchResults); err != nil {
+		return nil, err
+	}
+
+	messages := buildMessages(question, searchResults)
+
+	fmt.Println("Streaming LLM answer...")
+	llmStart := time.Now()
+	answer, usage, err := s.llm.ChatStream(ctx, messages, cb.OnToken)
+	trace.llm = time.Since(llmStart)
+	trace.usage.Add(usage)
+	if err != nil {
+		fmt.Printf("ERROR: LLM stream failed: %v\n", err)
+		return nil, fmt.Errorf("LLM stream failed: %w", err)
+	}
+
+	fmt.Printf("Answer streamed (length: %d chars)\n", len(answer))
+	resp = citedResponse(answer, searchResults)
+	s.checkGroundedness(ctx, resp)
+
+	fmt.Printf("=== STREAM QUERY COMPLETE ===\n\n")
+
+	return s.finish(ctx, session, req, question, resp), nil
+}
+
+// prepareQuestion resolves the conversation session and, for follow-ups,
+// rewrites the question into a standalone one that can be retrieved on.
+func (s *ChatService) prepareQuestion(ctx context.Context, req *QueryRequest, trace *queryTrace) (*domain.Session, string, error) {
+	session, history, err := s.sessionService.Resolve(ctx, req.WorkspaceID, req.SessionID, req.Query)
+	if err != nil {
+		return nil, "", err
+	}
+	fmt.Printf("Session: %s (%d previous turns)\n", session.ID, len(history))
+
+	trace.question = s.rewriteQuestion(ctx, req.Query, history, trace)
+	return session, trace.question, nil
+}
+
+// rewriteQuestion turns a follow-up like "what about page 5?" into a
+// standalone question using the previous turns. On failure the original
+// question is used unchanged.
+func (s *ChatService) rewriteQuestion(ctx context.Context, query string, history []*domain.SessionTurn, trace *queryTrace) string {
+	if len(history) == 0 {
+		return query
+	}
+
+	var transcript strings.Builder
+	for _, turn := range history {
+		question := turn.RewrittenQuery
+		if question == "" {
+			question = turn.Query
+		}
+		fmt.Fprintf(&transcript, "User: %s\nAssistant: %s\n\n", question, truncateRunes(turn.Answer, 500))
+	}
+
+	systemPrompt := `You rewrite follow-up questions into standalone questions.
+
+RULES:
+1. Use the conversation only to resolve references such as "it", "that", "the second one" or "what about page 5"
+2. Keep the meaning and language of the follow-up question
+3. If the question is already standalone, return it unchanged
+4. Return ONLY the rewritten question, without quotes or explanations`
+
+	userPrompt := fmt.Sprintf(`Conversation:
+%s
+Follow-up question: %s
+
+Standalone question:`, transcript.String(), query)
+
+	messages := []client.ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+
+	rewritten, usage, err := s.llm.Chat(ctx, messages)
+	trace.usage.Add(usage)
+	if err != nil {
+		fmt.Printf("WARNING: Failed to rewrite follow-up question, using original: %v\n", err)
+		return query
+	}
+
+	rewritten = strings.Trim(strings.TrimSpace(rewritten), `"'`)
+	if rewritten == "" {
+		return query
+	}
+
+	fmt.Printf("Rewritten query: %s\n", rewritten)
+	return rewritten
+}
+
+// finish attaches the session to the response and records the turn. A failure
+// to record the turn does not fail the query; it is reported through
+// SessionSaved, and a new session, which is only stored with its first turn,
+// is then left out of the response since it does not exist.
+func (s *ChatService) finish(ctx context.Context, session *domain.Session, req *QueryRequest, question string, resp *QueryResponse) *QueryResponse {
+	resp.SessionID = session.ID
+	if question != req.Query {
+		resp.RewrittenQuery = question
+	}
+
+	if err := s.sessionService.AppendTurn(ctx, session, req, question, resp); err != nil {
+		fmt.Printf("WARNING: Failed to record turn in session %s: %v\n", session.ID, err)
+		if req.SessionID == "" {
+			resp.SessionID = ""
+		}
+		return resp
+	}
+	resp.SessionSaved = true
+
+	return resp
+}
+
+// retrieve searches for the query, drops chunks below the similarity
+// threshold and reranks the rest, keeping the configured top N. When nothing
+// relevant is found it returns a ready-made response instead of results.
+func (s *ChatService) retrieve(ctx context.Context, query string, req *QueryRequest, trace *queryTrace) ([]*domain.SearchResult, *QueryResponse, error) {
+	// Search time excludes the query embedding, which is traced separately
+	start := time.Now()
+	defer func() { trace.search = time.Since(start) - trace.embed }()
+
+	searchResults, err := s.search(ctx, query, req, trace)
+	if err != nil {
+		return nil, nil, err
 	}
 	fmt.Printf("Found %d search results\n", len(searchResults))
 
 	if len(searchResults) == 0 {
 		fmt.Println("WARNING: No search results found")
-		return &QueryResponse{
+		return nil, &QueryResponse{
 			Answer:    "No relevant information found in the documents.",
 			Citations: []*domain.SearchResult{},
+			Markers:   []CitationMarker{},
 		}, nil
 	}
 
-	// Filter by similarity threshold (only keep results with score > 0.3)
-	const similarityThreshold = 0.3
+	// Filter by similarity threshold (only keep results with vector score above it).
+	// Keyword matches on identifiers are kept regardless: exact hits on part
+	// numbers or clause IDs often have low semantic similarity, while a
+	// shared ordinary word says little about relevance. Keyword-only search
+	// has no similarity to check, so its matches are all kept.
+	similarityThreshold := s.config.Search.SimilarityThreshold
+	relevant := func(result *domain.SearchResult) bool {
+		if trace.searchMode == SearchModeKeyword {
+			return true
+		}
+		return result.IdentifierMatch || result.VectorScore > similarityThreshold
+	}
+
 	var filteredResults []*domain.SearchResult
 	for _, result := range searchResults {
-		if result.Score > similarityThreshold {
+		if relevant(result) {
 			filteredResults = append(filteredResults, result)
 		}
 	}
@@ -82,24 +346,172 @@ func (s *ChatService) Query(ctx context.Context, req *QueryRequest) (*QueryRespo
 	fmt.Println("\nTop search results:")
 	for i, result := range searchResults {
 		status := "✓"
-		if result.Score <= similarityThreshold {
+		if !relevant(result) {
 			status = "✗ (filtered out)"
 		}
-		fmt.Printf("  %s %d. Page %d, Chunk %d, Score: %.4f, Content: %.100s...\n",
-			status, i+1, result.PageNumber, result.ChunkIndex, result.Score, result.Content)
+		fmt.Printf("  %s %d. Page %d, Chunk %d, Score: %.4f (vector: %.4f, keyword: %.4f), Content: %.100s...\n",
+			status, i+1, result.PageNumber, result.ChunkIndex, result.Score, result.VectorScore, result.KeywordScore, result.Content)
 	}
 
 	// Use filtered results or return no relevant info
 	if len(filteredResults) == 0 {
 		fmt.Printf("WARNING: All results below similarity threshold (%.2f)\n", similarityThreshold)
-		return &QueryResponse{
+		return nil, &QueryResponse{
 			Answer:    "No sufficiently relevant information found in the documents. The query may not be related to the document content.",
 			Citations: []*domain.SearchResult{},
+			Markers:   []CitationMarker{},
 		}, nil
 	}
-	searchResults = filteredResults
 	fmt.Printf("\nUsing %d results above threshold (%.2f)\n", len(filteredResults), similarityThreshold)
 
+	rerankedResults := s.rerank(ctx, query, filteredResults)
+	if len(rerankedResults) == 0 {
+		fmt.Println("WARNING: No results left after reranking")
+		return nil, &QueryResponse{
+			Answer:    "No sufficiently relevant information found in the documents. The query may not be related to the document content.",
+			Citations: []*domain.SearchResult{},
+			Markers:   []CitationMarker{},
+		}, nil
+	}
+
+	return rerankedResults, nil, nil
+}
+
+// rerank reorders the candidates with the configured reranker and keeps the
+// top N above the minimum score. If reranking fails the retrieval order is
+// kept so the query can still be answered.
+func (s *ChatService) rerank(ctx context.Context, query string, candidates []*domain.SearchResult) []*domain.SearchResult {
+	topN := s.config.Rerank.TopN
+
+	fmt.Printf("Reranking %d candidates (provider: %s, top %d)...\n", len(candidates), s.config.Rerank.Provider, topN)
+	reranked, err := s.reranker.Rerank(ctx, query, candidates)
+	if err != nil {
+		fmt.Printf("WARNING: Reranking failed, keeping retrieval order: %v\n", err)
+		reranked = candidates
+	} else if minScore := s.config.Rerank.MinScore; minScore > 0 {
+		kept := reranked[:0]
+		for _, result := range reranked {
+			if result.RerankScore >= minScore {
+				kept = append(kept, result)
+			}
+		}
+		fmt.Printf("Kept %d/%d reranked results with score >= %.2f\n", len(kept), len(reranked), minScore)
+		reranked = kept
+	}
+
+	if topN > 0 && len(reranked) > topN {
+		reranked = reranked[:topN]
+	}
+
+	for i, result := range reranked {
+		fmt.Printf("  %d. Page %d, Chunk %d, Rerank score: %.4f\n", i+1, result.PageNumber, result.ChunkIndex, result.RerankScore)
+	}
+
+	return reranked
+}
+
+// Search returns the ranked retrieval candidates for a query, before the
+// similarity threshold and reranking are applied. It does not record the
+// query or touch sessions.
+func (s *ChatService) Search(ctx context.Context, req *QueryRequest) ([]*domain.SearchResult, error) {
+	if err := s.resolveDocuments(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.search(ctx, req.Query, req, newQueryTrace())
+}
+
+// resolveDocuments expands the requested collections into DocumentIDs, so
+// the searched documents are recorded with the query
+func (s *ChatService) resolveDocuments(ctx context.Context, req *QueryRequest) error {
+	if req.AllDocuments || len(req.CollectionIDs) == 0 {
+		return nil
+	}
+
+	members, err := s.collections.ExpandDocuments(ctx, req.WorkspaceID, req.CollectionIDs)
+	if err != nil {
+		return err
+	}
+	req.DocumentIDs = uniqueIDs(append(req.DocumentIDs, members...))
+	fmt.Printf("Collections %v expanded to %d documents\n", req.CollectionIDs, len(req.DocumentIDs))
+	return nil
+}
+
+// search runs vector search, keyword search or both depending on the
+// requested mode, fusing the two rankings in hybrid mode.
+func (s *ChatService) search(ctx context.Context, query string, req *QueryRequest, trace *queryTrace) ([]*domain.SearchResult, error) {
+	topK := s.config.Rerank.CandidateCount
+
+	mode := req.SearchMode
+	if mode == "" {
+		mode = s.config.Search.Mode
+	}
+	vectorWeight := s.config.Search.VectorWeight
+	if req.VectorWeight != nil {
+		vectorWeight = *req.VectorWeight
+	}
+	trace.searchMode = mode
+
+	// A nil document list searches the whole workspace, so an empty one
+	// (e.g. from empty collections) must not reach the repository
+	documentIDs := req.DocumentIDs
+	if req.AllDocuments {
+		documentIDs = nil
+	} else if len(documentIDs) == 0 {
+		return nil, nil
+	}
+
+	// Queries are answered from the active embedding space
+	space, err := s.spaceRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active embedding space: %w", err)
+	}
+
+	var vectorResults, keywordResults []*domain.SearchResult
+
+	if mode != SearchModeKeyword {
+		// Generate query embedding
+		embedStart := time.Now()
+		queryEmbedding, err := s.embeddings.EmbedOne(ctx, space, query)
+		trace.embed = time.Since(embedStart)
+		if err != nil {
+			fmt.Printf("ERROR: Failed to generate query embedding: %v\n", err)
+			return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+		}
+		fmt.Printf("Query embedding generated (dim: %d, first 5 values: [%.4f, %.4f, %.4f, %.4f, %.4f])\n",
+			len(queryEmbedding), queryEmbedding[0], queryEmbedding[1], queryEmbedding[2], queryEmbedding[3], queryEmbedding[4])
+
+		// Vector search - get more results for better coverage
+		fmt.Printf("Performing vector search (top %d results)...\n", topK)
+		vectorResults, err = s.chunkRepo.VectorSearch(ctx, req.WorkspaceID, space, queryEmbedding, documentIDs, req.Filters, topK)
+		if err != nil {
+			fmt.Printf("ERROR: Vector search failed: %v\n", err)
+			return nil, fmt.Errorf("vector search failed: %w", err)
+		}
+	}
+
+	if mode != SearchModeVector {
+		fmt.Printf("Performing keyword search (top %d results)...\n", topK)
+		keywordResults, err = s.chunkRepo.KeywordSearch(ctx, req.WorkspaceID, space.ID, query, documentIDs, req.Filters, topK)
+		if err != nil {
+			fmt.Printf("ERROR: Keyword search failed: %v\n", err)
+			return nil, fmt.Errorf("keyword search failed: %w", err)
+		}
+	}
+
+	switch mode {
+	case SearchModeVector:
+		return vectorResults, nil
+	case SearchModeKeyword:
+		return keywordResults, nil
+	default:
+		fmt.Printf("Fusing %d vector and %d keyword results (vector weight: %.2f)\n",
+			len(vectorResults), len(keywordResults), vectorWeight)
+		return fuseResults(vectorResults, keywordResults, vectorWeight, topK), nil
+	}
+}
+
+// buildMessages assembles the system and user prompts from the retrieved chunks.
+func buildMessages(query string, searchResults []*domain.SearchResult) []client.ChatMessage {
 	// Build context from top results
 	var contextParts []string
 	for i, result := range searchResults {
@@ -131,26 +543,10 @@ Instructions:
 - Answer ONLY based on the context above
 - Cite sources using [Source X] format
 - If the context doesn't answer the question, say so explicitly
-- Do not use external knowledge`, context, req.Query)
+- Do not use external knowledge`, context, query)
 
-	// Call LLM
-	messages := []client.ChatMessage{
+	return []client.ChatMessage{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: userPrompt},
 	}
-
-	fmt.Println("Calling LLM for answer generation...")
-	answer, err := s.llmClient.Chat(messages)
-	if err != nil {
-		fmt.Printf("ERROR: LLM call failed: %v\n", err)
-		return nil, fmt.Errorf("LLM call failed: %w", err)
-	}
-
-	fmt.Printf("Answer generated (length: %d chars)\n", len(answer))
-	fmt.Printf("=== QUERY COMPLETE ===\n\n")
-
-	return &QueryResponse{
-		Answer:    answer,
-		Citations: searchResults,
-	}, nil
 }
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+// CitationMarker links a [Source N] marker in the answer to the chunk it
+// cites. Offsets are in characters (runes) of the normalized answer.
+type CitationMarker struct {
+	// Source is the marker number; Citations[Source-1] is the cited chunk
+	Source  int    `json:"source"`
+	ChunkID string `json:"chunk_id"`
+	// Start and End delimit the marker itself
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// SpanStart and SpanEnd delimit the answer text the marker supports: the
+	// sentence (or clause since the previous marker) ending at the marker
+	SpanStart int    `json:"span_start"`
+	SpanEnd   int    `json:"span_end"`
+	Text      string `json:"text"`
+}
+
+// sourceMarkerPattern matches the ways models write source markers:
+// [Source 1], [Sources 1, 2], [Source 1 and Source 3], (Source 2), [Source 1-3]
+var sourceMarkerPattern = regexp.MustCompile(
+	`(?i)[\[(]\s*sources?\s*(\d+(?:\s*(?:,|;|and|&|-|–)\s*(?:sources?\s*)?\d+)*)\s*[\])]`)
+
+var (
+	sourceRangePattern = regexp.MustCompile(`(\d+)\s*[-–]\s*(?:sources?\s*)?(\d+)|(\d+)`)
+	// A period inside "4.2" or "e.g." without a following space is not a boundary
+	sentenceEndPattern = regexp.MustCompile(`[.!?]\s+|[。\n]`)
+)
+
+// maxMarkerRangeWidth bounds how many sources a range marker like
+// [Source 1-3] expands to
+const maxMarkerRangeWidth = 20
+
+// citedAnswer is an answer with normalized markers and the chunks it cites
+type citedAnswer struct {
+	answer    string
+	citations []*domain.SearchResult
+	markers   []CitationMarker
+	// invalidSources are marker numbers that did not match a source; their
+	// markers are removed from the answer
+	invalidSources []int
+	// uncited is set when the answer has no valid markers; citations then
+	// holds every chunk the answer was generated from
+	uncited bool
+}
+
+// normalizeCitations parses the [Source N] markers of an answer generated
+// from sources (numbered from 1 in prompt order). Markers are rewritten as
+// one [Source N] per cited source, renumbered in order of first citation so
+// that N indexes the narrowed citations. Markers for sources that do not
+// exist are stripped and reported.
+func normalizeCitations(answer string, sources []*domain.SearchResult) *citedAnswer {
+	result := &citedAnswer{}
+
+	matches := sourceMarkerPattern.FindAllStringSubmatchIndex(answer, -1)
+
+	renumbered := make(map[int]int) // prompt source number -> cited number
+	invalid := make(map[int]bool)
+
+	var out strings.Builder
+	outRunes := 0
+	last := 0      // byte offset in answer copied up to
+	spanStart := 0 // rune offset in out where the current span begins
+	var prevSpan span
+	afterMarker := false
+	// pendingSpace is the whitespace before a stripped marker; it is restored
+	// unless punctuation follows ("fact [Source 9]." -> "fact.")
+	pendingSpace := ""
+	withPending := func(text string) string {
+		if pendingSpace != "" && (text == "" || !strings.ContainsRune(" \n.,;:!?)", []rune(text)[0])) {
+			text = pendingSpace + text
+		}
+		pendingSpace = ""
+		return text
+	}
+
+	write := func(s string) {
+		out.WriteString(s)
+		outRunes += utf8.RuneCountInString(s)
+	}
+
+	for _, m := range matches {
+		text := withPending(answer[last:m[0]])
+		numbers := parseSourceNumbers(answer[m[2]:m[3]])
+
+		var valid []int
+		for _, n := range numbers {
+			if n < 1 || n > len(sources) {
+				if !invalid[n] {
+					invalid[n] = true
+					result.invalidSources = append(result.invalidSources, n)
+				}
+				continue
+			}
+			valid = append(valid, n)
+		}
+
+		if len(valid) == 0 {
+			// Strip the marker together with the space before it
+			trimmed := strings.TrimRight(text, " ")
+			pendingSpace = text[len(trimmed):]
+			write(trimmed)
+			if strings.TrimSpace(trimmed) != "" {
+				afterMarker = false
+			}
+			last = m[1]
+			continue
+		}
+
+		write(text)
+		// Adjacent markers ("[Source 1][Source 2]") support the same text
+		supported := prevSpan
+		if !afterMarker || strings.TrimSpace(text) != "" {
+			supported = answerSpan(out.String(), spanStart, outRunes)
+		}
+		prevSpan = supported
+		afterMarker = true
+
+		seen := make(map[int]bool, len(valid))
+		for _, n := range valid {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+
+			cited, ok := renumbered[n]
+			if !ok {
+				result.citations = append(result.citations, sources[n-1])
+				cited = len(result.citations)
+				renumbered[n] = cited
+			}
+
+			marker := "[Source " + strconv.Itoa(cited) + "]"
+			start := outRunes
+			write(marker)
+
+			result.markers = append(result.markers, CitationMarker{
+				Source:    cited,
+				ChunkID:   sources[n-1].ID,
+				Start:     start,
+				End:       outRunes,
+				SpanStart: supported.start,
+				SpanEnd:   supported.end,
+				Text:      supported.text,
+			})
+		}
+
+		spanStart = outRunes
+		last = m[1]
+	}
+	write(withPending(answer[last:]))
+
+	result.answer = out.String()
+	if len(result.citations) == 0 {
+		result.uncited = true
+		result.citations = sources
+	}
+	return result
+}
+
+// parseSourceNumbers reads "1, 2", "1 and Source 3" or "1-3" into numbers.
+// Ranges wider than maxMarkerRangeWidth are read as their two end points.
+func parseSourceNumbers(s string) []int {
+	var numbers []int
+	for _, m := range sourceRangePattern.FindAllStringSubmatch(s, -1) {
+		if m[3] != "" {
+			n, _ := strconv.Atoi(m[3])
+			numbers = append(numbers, n)
+			continue
+		}
+
+		from, _ := strconv.Atoi(m[1])
+		to, _ := strconv.Atoi(m[2])
+		if to < from || to-from > maxMarkerRangeWidth {
+			numbers = append(numbers, from, to)
+			continue
+		}
+		for n := from; n <= to; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+type span struct {
+	start, end int
+	text       string
+}
+
+// answerSpan returns the text a marker at the end of written supports: from
+// the last sentence boundary (or the previous marker, whichever is later) to
+// the end, with surrounding whitespace trimmed. Offsets are in runes.
+func answerSpan(written string, minStart, end int) span {
+	runes := []rune(written)
+
+	start := minStart
+	// Skip the sentence terminator directly before the marker ("fact. [Source 1]")
+	searchEnd := end
+	for searchEnd > start && strings.ContainsRune(" .!?。", runes[searchEnd-1]) {
+		searchEnd--
+	}
+	if locs := sentenceEndPattern.FindAllStringIndex(string(runes[start:searchEnd]), -1); len(locs) > 0 {
+		boundary := locs[len(locs)-1][1]
+		start += utf8.RuneCountInString(string(runes[start:searchEnd])[:boundary])
+	}
+
+	for start < end && isSpace(runes[start]) {
+		start++
+	}
+	spanEnd := end
+	for spanEnd > start && isSpace(runes[spanEnd-1]) {
+		spanEnd--
+	}
+
+	return span{start: start, end: spanEnd, text: string(runes[start:spanEnd])}
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// citedResponse builds the response for an answer generated from sources,
+// with normalized markers and the citations narrowed to the cited chunks
+func citedResponse(answer string, sources []*domain.SearchResult) *QueryResponse {
+	cited := normalizeCitations(answer, sources)
+
+	if len(cited.invalidSources) > 0 {
+		fmt.Printf("WARNING: Answer cites unknown sources %v (%d available), markers removed\n", cited.invalidSources, len(sources))
+	}
+	if cited.uncited {
+		fmt.Println("WARNING: Answer has no source markers, returning all sources as citations")
+	} else {
+		fmt.Printf("Answer cites %d of %d sources\n", len(cited.citations), len(sources))
+	}
+
+	markers := cited.markers
+	if markers == nil {
+		markers = []CitationMarker{}
+	}
+
+	return &QueryResponse{
+		Answer:         cited.answer,
+		Citations:      cited.citations,
+		Markers:        markers,
+		InvalidSources: cited.invalidSources,
+		Uncited:        cited.uncited,
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+func testSources(ids ...string) []*domain.SearchResult {
+	sources := make([]*domain.SearchResult, 0, len(ids))
+	for _, id := range ids {
+		source := &domain.SearchResult{}
+		source.ID = id
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+func citationIDs(citations []*domain.SearchResult) []string {
+	ids := make([]string, 0, len(citations))
+	for _, citation := range citations {
+		ids = append(ids, citation.ID)
+	}
+	return ids
+}
+
+func TestNormalizeCitations(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    string
+		sources   []string
+		want      string
+		citations []string
+		markers   []CitationMarker
+		invalid   []int
+		uncited   bool
+	}{
+		{
+			name:      "list marker is split per source",
+			answer:    "A is true [Sources 1, 2].",
+			sources:   []string{"a", "b", "c"},
+			want:      "A is true [Source 1][Source 2].",
+			citations: []string{"a", "b"},
+			markers: []CitationMarker{
+				{Source: 1, ChunkID: "a", Start: 10, End: 20, SpanStart: 0, SpanEnd: 9, Text: "A is true"},
+				{Source: 2, ChunkID: "b", Start: 20, End: 30, SpanStart: 0, SpanEnd: 9, Text: "A is true"},
+			},
+		},
+		{
+			name:      "range marker is expanded",
+			answer:    "B holds [Source 1-3]",
+			sources:   []string{"a", "b", "c"},
+			want:      "B holds [Source 1][Source 2][Source 3]",
+			citations: []string{"a", "b", "c"},
+			markers: []CitationMarker{
+				{Source: 1, ChunkID: "a", Start: 8, End: 18, SpanStart: 0, SpanEnd: 7, Text: "B holds"},
+				{Source: 2, ChunkID: "b", Start: 18, End: 28, SpanStart: 0, SpanEnd: 7, Text: "B holds"},
+				{Source: 3, ChunkID: "c", Start: 28, End: 38, SpanStart: 0, SpanEnd: 7, Text: "B holds"},
+			},
+		},
+		{
+			name:      "out of range marker is stripped with its space",
+			answer:    "C is so [Source 9].",
+			sources:   []string{"a", "b"},
+			want:      "C is so.",
+			citations: []string{"a", "b"},
+			invalid:   []int{9},
+			uncited:   true,
+		},
+		{
+			name:      "adjacent markers share a span and are renumbered by first citation",
+			answer:    "D [Source 2][Source 1]. E [Source 2]",
+			sources:   []string{"a", "b"},
+			want:      "D [Source 1][Source 2]. E [Source 1]",
+			citations: []string{"b", "a"},
+			markers: []CitationMarker{
+				{Source: 1, ChunkID: "b", Start: 2, End: 12, SpanStart: 0, SpanEnd: 1, Text: "D"},
+				{Source: 2, ChunkID: "a", Start: 12, End: 22, SpanStart: 0, SpanEnd: 1, Text: "D"},
+				{Source: 1, ChunkID: "b", Start: 26, End: 36, SpanStart: 24, SpanEnd: 25, Text: "E"},
+			},
+		},
+		{
+			name:      "offsets count runes of multibyte text",
+			answer:    "가격은 100원입니다 [Source 1]",
+			sources:   []string{"a"},
+			want:      "가격은 100원입니다 [Source 1]",
+			citations: []string{"a"},
+			markers: []CitationMarker{
+				{Source: 1, ChunkID: "a", Start: 12, End: 22, SpanStart: 0, SpanEnd: 11, Text: "가격은 100원입니다"},
+			},
+		},
+		{
+			name:      "answer without markers cites every source",
+			answer:    "Plain answer.",
+			sources:   []string{"a", "b"},
+			want:      "Plain answer.",
+			citations: []string{"a", "b"},
+			uncited:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeCitations(tt.answer, testSources(tt.sources...))
+
+			if got.answer != tt.want {
+				t.Errorf("answer = %q, want %q", got.answer, tt.want)
+			}
+			if ids := citationIDs(got.citations); !reflect.DeepEqual(ids, tt.citations) {
+				t.Errorf("citations = %v, want %v", ids, tt.citations)
+			}
+			if !reflect.DeepEqual(got.markers, tt.markers) {
+				t.Errorf("markers = %+v, want %+v", got.markers, tt.markers)
+			}
+			if !reflect.DeepEqual(got.invalidSources, tt.invalid) {
+				t.Errorf("invalid sources = %v, want %v", got.invalidSources, tt.invalid)
+			}
+			if got.uncited != tt.uncited {
+				t.Errorf("uncited = %v, want %v", got.uncited, tt.uncited)
+			}
+		})
+	}
+}
+
+func TestParseSourceNumbers(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"1", []int{1}},
+		{"1, 2", []int{1, 2}},
+		{"1 and Source 3", []int{1, 3}},
+		{"1-3", []int{1, 2, 3}},
+		{"2 – 4", []int{2, 3, 4}},
+		// Reversed and overly wide ranges keep only their end points
+		{"3-1", []int{3, 1}},
+		{"1-100", []int{1, 100}},
+	}
+
+	for _, tt := range tests {
+		if got := parseSourceNumbers(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSourceNumbers(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnswerSpan(t *testing.T) {
+	tests := []struct {
+		name     string
+		written  string
+		minStart int
+		want     span
+	}{
+		{"sentence before marker", "First. Second fact ", 0, span{start: 7, end: 18, text: "Second fact"}},
+		{"terminator before marker is skipped", "First. Second fact. ", 0, span{start: 7, end: 19, text: "Second fact."}},
+		{"decimal point is not a boundary", "Clause 4.2 applies ", 0, span{start: 0, end: 18, text: "Clause 4.2 applies"}},
+		{"previous marker bounds the span", "One [Source 1] and two ", 14, span{start: 15, end: 22, text: "and two"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := answerSpan(tt.written, tt.minStart, len([]rune(tt.written)))
+			if got != tt.want {
+				t.Errorf("answerSpan = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
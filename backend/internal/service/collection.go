@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCollectionNotFound is returned when a collection ID does not exist
+	// in the workspace
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrInvalidCollection is returned for a collection that cannot be saved
+	ErrInvalidCollection = errors.New("invalid collection")
+	// ErrCollectionExists is returned when the workspace already has a
+	// collection with the name
+	ErrCollectionExists = errors.New("collection already exists")
+)
+
+// CollectionService manages named groups of documents and expands them into
+// the documents to search
+type CollectionService struct {
+	repo    *repository.CollectionRepository
+	docRepo *repository.DocumentRepository
+}
+
+func NewCollectionService(repo *repository.CollectionRepository, docRepo *repository.DocumentRepository) *CollectionService {
+	return &CollectionService{
+		repo:    repo,
+		docRepo: docRepo,
+	}
+}
+
+// CollectionRequest creates a collection or replaces its name and
+// description. DocumentIDs is only used on create; membership is changed
+// through AddDocuments and RemoveDocuments afterwards.
+type CollectionRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	DocumentIDs []string `json:"document_ids"`
+}
+
+// CollectionDocumentsRequest lists documents to add to or remove from a
+// collection
+type CollectionDocumentsRequest struct {
+	DocumentIDs []string `json:"document_ids"`
+}
+
+// CollectionDetails is a collection with its member documents
+type CollectionDetails struct {
+	*domain.Collection
+	DocumentIDs []string `json:"document_ids"`
+}
+
+func (s *CollectionService) Create(ctx context.Context, workspaceID string, req CollectionRequest) (*CollectionDetails, error) {
+	collection := &domain.Collection{
+		ID:          uuid.New().String(),
+		WorkspaceID: workspaceID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.applyRequest(ctx, collection, req); err != nil {
+		return nil, err
+	}
+	documentIDs, err := s.checkDocuments(ctx, workspaceID, req.DocumentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, collection, documentIDs); err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return s.Get(ctx, workspaceID, collection.ID)
+}
+
+func (s *CollectionService) List(ctx context.Context, workspaceID string) ([]*domain.Collection, error) {
+	return s.repo.List(ctx, workspaceID)
+}
+
+// Get returns the collection with its member documents
+func (s *CollectionService) Get(ctx context.Context, workspaceID, id string) (*CollectionDetails, error) {
+	collection, err := s.get(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	documentIDs, err := s.repo.DocumentIDs(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection documents: %w", err)
+	}
+	return &CollectionDetails{Collection: collection, DocumentIDs: documentIDs}, nil
+}
+
+// Update replaces the name and description of a collection
+func (s *CollectionService) Update(ctx context.Context, workspaceID, id string, req CollectionRequest) (*CollectionDetails, error) {
+	collection, err := s.get(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(ctx, collection, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, collection); err != nil {
+		return nil, fmt.Errorf("failed to update collection: %w", err)
+	}
+	return s.Get(ctx, workspaceID, id)
+}
+
+// Delete removes a collection; its documents are kept
+func (s *CollectionService) Delete(ctx context.Context, workspaceID, id string) error {
+	if _, err := s.get(ctx, workspaceID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// AddDocuments adds documents of the workspace to the collection; documents
+// already in it are skipped
+func (s *CollectionService) AddDocuments(ctx context.Context, workspaceID, id string, documentIDs []string) (*CollectionDetails, error) {
+	if _, err := s.get(ctx, workspaceID, id); err != nil {
+		return nil, err
+	}
+	documentIDs, err := s.checkDocuments(ctx, workspaceID, documentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(documentIDs) == 0 {
+		return nil, fmt.Errorf("%w: document_ids is required", ErrInvalidCollection)
+	}
+
+	if err := s.repo.AddDocuments(ctx, id, documentIDs); err != nil {
+		return nil, fmt.Errorf("failed to add documents to collection: %w", err)
+	}
+	return s.Get(ctx, workspaceID, id)
+}
+
+// RemoveDocuments removes documents from the collection; the documents
+// themselves are kept
+func (s *CollectionService) RemoveDocuments(ctx context.Context, workspaceID, id string, documentIDs []string) (*CollectionDetails, error) {
+	if _, err := s.get(ctx, workspaceID, id); err != nil {
+		return nil, err
+	}
+	documentIDs = uniqueIDs(documentIDs)
+	if len(documentIDs) == 0 {
+		return nil, fmt.Errorf("%w: document_ids is required", ErrInvalidCollection)
+	}
+
+	if err := s.repo.RemoveDocuments(ctx, id, documentIDs); err != nil {
+		return nil, fmt.Errorf("failed to remove documents from collection: %w", err)
+	}
+	return s.Get(ctx, workspaceID, id)
+}
+
+// ExpandDocuments returns the distinct documents of the given collections,
+// failing with ErrCollectionNotFound if any of them is not in the workspace
+func (s *CollectionService) ExpandDocuments(ctx context.Context, workspaceID string, collectionIDs []string) ([]string, error) {
+	collectionIDs = uniqueIDs(collectionIDs)
+	if len(collectionIDs) == 0 {
+		return nil, nil
+	}
+
+	count, err := s.repo.CountInWorkspace(ctx, workspaceID, collectionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+	if count != int64(len(collectionIDs)) {
+		return nil, ErrCollectionNotFound
+	}
+
+	documentIDs, err := s.repo.MemberDocumentIDs(ctx, workspaceID, collectionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection documents: %w", err)
+	}
+	return documentIDs, nil
+}
+
+func (s *CollectionService) get(ctx context.Context, workspaceID, id string) (*domain.Collection, error) {
+	collection, err := s.repo.GetByID(ctx, workspaceID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection, nil
+}
+
+// applyRequest validates req and copies it onto collection. Names are
+// unique within a workspace.
+func (s *CollectionService) applyRequest(ctx context.Context, collection *domain.Collection, req CollectionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCollection)
+	}
+
+	existing, err := s.repo.GetByName(ctx, collection.WorkspaceID, name)
+	if err == nil && existing.ID != collection.ID {
+		return fmt.Errorf("%w: %s", ErrCollectionExists, name)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	collection.Name = name
+	collection.Description = strings.TrimSpace(req.Description)
+	return nil
+}
+
+// checkDocuments removes duplicates from documentIDs and makes sure they are
+// all documents of the workspace
+func (s *CollectionService) checkDocuments(ctx context.Context, workspaceID string, documentIDs []string) ([]string, error) {
+	documentIDs = uniqueIDs(documentIDs)
+	if len(documentIDs) == 0 {
+		return nil, nil
+	}
+
+	count, err := s.docRepo.CountInWorkspace(ctx, workspaceID, documentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+	if count != int64(len(documentIDs)) {
+		return nil, fmt.Errorf("%w: %d of the documents do not exist", ErrInvalidCollection, int64(len(documentIDs))-count)
+	}
+	return documentIDs, nil
+}
+
+// uniqueIDs drops empty and repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
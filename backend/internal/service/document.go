@@ -2,59 +2,137 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/pdf-rag-system/backend/internal/client"
 	"github.com/pdf-rag-system/backend/internal/domain"
 	"github.com/pdf-rag-system/backend/internal/repository"
 	"github.com/pdf-rag-system/backend/pkg/config"
+	pb "github.com/pdf-rag-system/backend/pkg/proto"
 	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
 )
 
+var (
+	// ErrDocumentNotFound is returned when a document ID does not exist
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrDocumentBusy is returned when a document is already being processed
+	ErrDocumentBusy = errors.New("document is still being processed")
+	// ErrInvalidIndexSettings is returned for unusable chunking settings
+	ErrInvalidIndexSettings = errors.New("invalid index settings")
+	// ErrNoFailedChunks is returned when retrying a document without failed chunks
+	ErrNoFailedChunks = errors.New("document has no failed chunks to retry")
+	// ErrInvalidDocumentUpdate is returned for unusable tags, metadata or
+	// descriptive fields
+	ErrInvalidDocumentUpdate = errors.New("invalid document update")
+	// ErrInvalidDocumentQuery is returned for unusable listing filters, sort
+	// or cursor
+	ErrInvalidDocumentQuery = errors.New("invalid document query")
+)
+
+// What Upload does when the file was uploaded before
+const (
+	// DuplicateExisting returns the existing document
+	DuplicateExisting = "existing"
+	// DuplicateAlias creates a new document that reuses the existing chunks
+	DuplicateAlias = "alias"
+)
+
+// ValidDuplicateMode reports whether mode is a known duplicate handling mode
+func ValidDuplicateMode(mode string) bool {
+	return mode == DuplicateExisting || mode == DuplicateAlias
+}
+
 type DocumentService struct {
 	docRepo         *repository.DocumentRepository
 	chunkRepo       *repository.ChunkRepository
+	jobRepo         *repository.JobRepository
+	spaceRepo       *repository.EmbeddingSpaceRepository
+	workspaces      *WorkspaceService
 	docreaderClient *client.DocReaderClient
-	llmClient       *client.LLMClient
+	embeddings      *EmbeddingService
 	config          *config.Config
 }
 
 func NewDocumentService(
 	docRepo *repository.DocumentRepository,
 	chunkRepo *repository.ChunkRepository,
+	jobRepo *repository.JobRepository,
+	spaceRepo *repository.EmbeddingSpaceRepository,
+	workspaces *WorkspaceService,
 	docreaderClient *client.DocReaderClient,
+	embeddings *EmbeddingService,
 	cfg *config.Config,
 ) *DocumentService {
-	llmClient := client.NewLLMClient(cfg.Embedding.APIBaseURL, cfg.Embedding.APIKey, cfg.Embedding.Model)
-
 	return &DocumentService{
 		docRepo:         docRepo,
 		chunkRepo:       chunkRepo,
+		jobRepo:         jobRepo,
+		spaceRepo:       spaceRepo,
+		workspaces:      workspaces,
 		docreaderClient: docreaderClient,
-		llmClient:       llmClient,
+		embeddings:      embeddings,
 		config:          cfg,
 	}
 }
 
-func (s *DocumentService) Upload(ctx context.Context, file io.Reader, filename string, fileSize int64) (*domain.Document, error) {
+// DocumentLabels are the tags and metadata a user assigns to a document
+type DocumentLabels struct {
+	Tags     []string
+	Metadata map[string]string
+}
+
+// DocumentUpdate edits a document's descriptive fields. Nil fields are left
+// unchanged; Tags replaces the tags, Metadata is merged with null values
+// removing keys, and an empty CreationDate clears it.
+type DocumentUpdate struct {
+	Title        *string            `json:"title"`
+	Author       *string            `json:"author"`
+	CreationDate *string            `json:"creation_date"`
+	Tags         *[]string          `json:"tags"`
+	Metadata     map[string]*string `json:"metadata"`
+}
+
+// Upload stores the file in the workspace with the given labels and queues
+// it for processing. Title, author and creation date are read from the PDF.
+// If a file with the same content was uploaded to the workspace before,
+// onDuplicate selects whether the existing document is returned or an alias
+// of it is created; duplicate reports which. Uploads beyond the workspace's
+// quotas fail with ErrQuotaExceeded.
+func (s *DocumentService) Upload(ctx context.Context, workspaceID string, file io.Reader, filename string, fileSize int64, onDuplicate string, labels DocumentLabels) (doc *domain.Document, duplicate bool, err error) {
 	fmt.Printf("=== UPLOAD SERVICE START ===\n")
 	fmt.Printf("Filename: %s, Size: %d bytes (%.2f MB)\n", filename, fileSize, float64(fileSize)/(1024*1024))
 
+	if err := validateMetadata(labels.Metadata); err != nil {
+		return nil, false, err
+	}
+
 	// Create document record
-	doc := &domain.Document{
-		ID:         uuid.New().String(),
-		Filename:   filename,
-		FileSize:   fileSize,
-		UploadTime: time.Now(),
-		Status:     "processing",
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	doc = &domain.Document{
+		ID:          uuid.New().String(),
+		WorkspaceID: workspaceID,
+		Filename:    filename,
+		FileSize:    fileSize,
+		UploadTime:  time.Now(),
+		Status:      domain.StatusProcessing,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+
+		Tags:     domain.NormalizeTags(labels.Tags),
+		Metadata: domain.Metadata(labels.Metadata),
+
+		ProcessingStage: domain.StageQueued,
 	}
 	fmt.Printf("Created document record with ID: %s\n", doc.ID)
 
@@ -63,7 +141,7 @@ func (s *DocumentService) Upload(ctx context.Context, file io.Reader, filename s
 	fmt.Printf("Upload directory: %s\n", uploadDir)
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
 		fmt.Printf("ERROR: Failed to create upload directory: %v\n", err)
-		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+		return nil, false, fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
 	filePath := filepath.Join(uploadDir, doc.ID+".pdf")
@@ -71,169 +149,914 @@ func (s *DocumentService) Upload(ctx context.Context, file io.Reader, filename s
 	outFile, err := os.Create(filePath)
 	if err != nil {
 		fmt.Printf("ERROR: Failed to create file: %v\n", err)
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return nil, false, fmt.Errorf("failed to create file: %w", err)
 	}
 	defer outFile.Close()
 
-	fmt.Println("Reading file content...")
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		fmt.Printf("ERROR: Failed to read file: %v\n", err)
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-	fmt.Printf("Read %d bytes from file\n", len(fileContent))
-
+	// Hash the file while writing it
 	fmt.Println("Writing file to disk...")
-	if _, err := outFile.Write(fileContent); err != nil {
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(outFile, hasher), file)
+	if err != nil {
 		fmt.Printf("ERROR: Failed to write file: %v\n", err)
-		return nil, fmt.Errorf("failed to write file: %w", err)
+		return nil, false, fmt.Errorf("failed to write file: %w", err)
 	}
-	fmt.Println("File written successfully")
+	fmt.Printf("File written successfully (%d bytes)\n", written)
 
 	doc.FilePath = filePath
+	doc.ContentHash = hex.EncodeToString(hasher.Sum(nil))
+	fmt.Printf("Content hash: %s\n", doc.ContentHash)
+
+	existing, err := s.docRepo.FindByContentHash(ctx, workspaceID, doc.ContentHash)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("failed to look up duplicates: %w", err)
+	}
+	if err == nil {
+		// The content is already stored; drop the new copy
+		outFile.Close()
+		if err := os.Remove(filePath); err != nil {
+			fmt.Printf("WARNING: Failed to remove duplicate file %s: %v\n", filePath, err)
+		}
+
+		if onDuplicate != DuplicateAlias {
+			fmt.Printf("Duplicate of document %s, returning it\n", existing.ID)
+			existing, err = s.mergeLabels(ctx, existing, labels)
+			if err != nil {
+				return nil, false, err
+			}
+			return existing, true, nil
+		}
+
+		alias, err := s.createAlias(ctx, doc, existing)
+		if err != nil {
+			return nil, false, err
+		}
+		fmt.Printf("Duplicate of document %s, created alias %s\n", existing.ID, alias.ID)
+		return alias, true, nil
+	}
+
+	// Missing or unreadable info only leaves the fields empty
+	info, err := readPDFInfo(ctx, filePath)
+	if err != nil {
+		fmt.Printf("WARNING: Failed to read PDF info of %s: %v\n", filename, err)
+	} else {
+		doc.Title = info.Title
+		doc.Author = info.Author
+		doc.CreationDate = info.CreationDate
+	}
 
 	// Save document to DB
 	fmt.Println("Saving document to database...")
-	if err := s.docRepo.Create(ctx, doc); err != nil {
+	if err := s.workspaces.CreateDocument(ctx, doc); err != nil {
+		outFile.Close()
+		if err := os.Remove(filePath); err != nil {
+			fmt.Printf("WARNING: Failed to remove file %s: %v\n", filePath, err)
+		}
+		if errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrWorkspaceNotFound) {
+			return nil, false, err
+		}
 		fmt.Printf("ERROR: Failed to save document to DB: %v\n", err)
-		return nil, fmt.Errorf("failed to save document: %w", err)
+		return nil, false, fmt.Errorf("failed to save document: %w", err)
 	}
 	fmt.Println("Document saved to database")
 
-	// Parse PDF via gRPC (async in background)
-	fmt.Println("Starting PDF processing in background...")
-	go s.processPDF(context.Background(), doc.ID, fileContent, filename)
+	// Queue PDF processing for the ingestion workers
+	if err := s.enqueue(ctx, doc.ID); err != nil {
+		fmt.Printf("ERROR: Failed to queue document %s for processing: %v\n", doc.ID, err)
+		// Without a job the document would stay processing, and a retried
+		// upload would get it back as a duplicate; remove it instead. The
+		// request may have been cancelled, so clean up regardless.
+		cleanupCtx := context.WithoutCancel(ctx)
+		if err := s.docRepo.Delete(cleanupCtx, doc.ID); err != nil {
+			fmt.Printf("WARNING: Failed to remove unqueued document %s: %v\n", doc.ID, err)
+		} else {
+			outFile.Close()
+			if err := os.Remove(filePath); err != nil {
+				fmt.Printf("WARNING: Failed to remove file %s: %v\n", filePath, err)
+			}
+		}
+		return nil, false, fmt.Errorf("failed to queue document for processing: %w", err)
+	}
+	fmt.Println("Document queued for processing")
 
 	fmt.Printf("=== UPLOAD SERVICE COMPLETE ===\n")
+	return doc, false, nil
+}
+
+// mergeLabels adds the tags and metadata of a duplicate upload to the
+// existing document, so they are not silently dropped. Metadata values of
+// the upload replace existing ones under the same key.
+func (s *DocumentService) mergeLabels(ctx context.Context, doc *domain.Document, labels DocumentLabels) (*domain.Document, error) {
+	if len(labels.Tags) == 0 && len(labels.Metadata) == 0 {
+		return doc, nil
+	}
+
+	doc.Tags = domain.NormalizeTags(append(append([]string{}, doc.Tags...), labels.Tags...))
+	metadata := make(domain.Metadata, len(doc.Metadata)+len(labels.Metadata))
+	for key, value := range doc.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range labels.Metadata {
+		metadata[key] = value
+	}
+	doc.Metadata = metadata
+
+	if err := s.docRepo.UpdateMetadata(ctx, doc); err != nil {
+		return nil, fmt.Errorf("failed to add labels to document %s: %w", doc.ID, err)
+	}
 	return doc, nil
 }
 
-func (s *DocumentService) processPDF(ctx context.Context, docID string, fileContent []byte, filename string) {
+// createAlias saves doc as an alias of existing, sharing its file and chunks
+// and mirroring its status and progress
+func (s *DocumentService) createAlias(ctx context.Context, doc, existing *domain.Document) (*domain.Document, error) {
+	alias := *existing
+	alias.ID = doc.ID
+	alias.Filename = doc.Filename
+	alias.UploadTime = doc.UploadTime
+	alias.CreatedAt = doc.CreatedAt
+	alias.UpdatedAt = doc.UpdatedAt
+	alias.AliasOf = &existing.ID
+	// The PDF's info is shared, the labels are the alias's own
+	alias.Tags = doc.Tags
+	alias.Metadata = doc.Metadata
+
+	if err := s.workspaces.CreateDocument(ctx, &alias); err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			return nil, err
+		}
+		fmt.Printf("ERROR: Failed to save alias to DB: %v\n", err)
+		return nil, fmt.Errorf("failed to save document: %w", err)
+	}
+	return &alias, nil
+}
+
+// enqueue creates an ingestion job that processes the whole document
+func (s *DocumentService) enqueue(ctx context.Context, docID string) error {
+	return s.enqueueKind(ctx, docID, domain.JobKindIngest, s.defaultIndexSettings())
+}
+
+func (s *DocumentService) enqueueKind(ctx context.Context, docID, kind string, settings domain.IndexSettings) error {
+	return s.createJob(ctx, &domain.IngestionJob{
+		DocumentID:    docID,
+		Kind:          kind,
+		IndexSettings: settings,
+	})
+}
+
+// enqueueSpaceBuild creates a job that stores the document's chunks in a
+// building embedding space
+func (s *DocumentService) enqueueSpaceBuild(ctx context.Context, docID, spaceID string) error {
+	return s.createJob(ctx, &domain.IngestionJob{
+		DocumentID:       docID,
+		Kind:             domain.JobKindEmbedSpace,
+		EmbeddingSpaceID: spaceID,
+	})
+}
+
+func (s *DocumentService) createJob(ctx context.Context, job *domain.IngestionJob) error {
+	job.ID = uuid.New().String()
+	job.Status = domain.JobStatusPending
+	job.MaxAttempts = s.config.Ingestion.MaxAttempts
+	job.RunAt = time.Now()
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = time.Now()
+	return s.jobRepo.Create(ctx, job)
+}
+
+// QueueSpaceBuild queues a job for every indexed document, in all
+// workspaces, that stores its chunks in the embedding space. It returns how
+// many jobs were queued.
+func (s *DocumentService) QueueSpaceBuild(ctx context.Context, space *domain.EmbeddingSpace) (int, error) {
+	docs, err := s.docRepo.ListAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, doc := range docs {
+		// Aliases share their original's chunks; documents being processed
+		// queue their own build once they finish
+		if doc.AliasOf != nil || (doc.Status != domain.StatusCompleted && doc.Status != domain.StatusPartial) {
+			continue
+		}
+		if err := s.enqueueSpaceBuild(ctx, doc.ID, space.ID); err != nil {
+			return queued, fmt.Errorf("failed to queue build of document %s: %w", doc.ID, err)
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// queueBuildingSpaces keeps spaces that are being built in step with a
+// document that was just (re)indexed
+func (s *DocumentService) queueBuildingSpaces(ctx context.Context, docID string) {
+	spaces, err := s.spaceRepo.ListByStatus(ctx, domain.SpaceStatusBuilding)
+	if err != nil {
+		fmt.Printf("WARNING: Failed to list building embedding spaces: %v\n", err)
+		return
+	}
+	for _, space := range spaces {
+		if err := s.enqueueSpaceBuild(ctx, docID, space.ID); err != nil {
+			fmt.Printf("WARNING: Failed to queue document %s for embedding space %s: %v\n", docID, space.ID, err)
+		}
+	}
+}
+
+// runJob executes an ingestion job. It is called by the ingestion workers,
+// which own retries and the final error status.
+func (s *DocumentService) runJob(ctx context.Context, job *domain.IngestionJob) error {
+	switch job.Kind {
+	case domain.JobKindRetryFailed:
+		return s.retryFailedChunks(ctx, job.DocumentID)
+	case domain.JobKindEmbedSpace:
+		return s.embedIntoSpace(ctx, job.DocumentID, job.EmbeddingSpaceID)
+	default:
+		return s.processPDF(ctx, job.DocumentID, s.withDefaults(job.IndexSettings))
+	}
+}
+
+// defaultIndexSettings returns the configured chunking. The embedding model
+// is that of the active embedding space.
+func (s *DocumentService) defaultIndexSettings() domain.IndexSettings {
+	return domain.IndexSettings{
+		ChunkSize:    s.config.Chunking.Size,
+		ChunkOverlap: s.config.Chunking.Overlap,
+	}
+}
+
+// withDefaults fills unset chunking settings from the configuration
+func (s *DocumentService) withDefaults(settings domain.IndexSettings) domain.IndexSettings {
+	if settings.ChunkSize <= 0 {
+		defaults := s.defaultIndexSettings()
+		settings.ChunkSize = defaults.ChunkSize
+		settings.ChunkOverlap = defaults.ChunkOverlap
+	}
+	return settings
+}
+
+// failJob records the final failure of a job on its document
+func (s *DocumentService) failJob(ctx context.Context, job *domain.IngestionJob, cause error) {
+	if job.Kind == domain.JobKindEmbedSpace {
+		// The document is served from the active space, which is untouched
+		fmt.Printf("ERROR: Document %s could not be stored in embedding space %s: %v\n", job.DocumentID, job.EmbeddingSpaceID, cause)
+		return
+	}
+
+	if job.Kind == domain.JobKindRetryFailed {
+		// The chunks stored earlier are still there, so the document stays usable
+		s.setProgress(ctx, job.DocumentID, map[string]interface{}{
+			"status":           domain.StatusPartial,
+			"processing_stage": domain.StageDone,
+			"eta":              nil,
+			"last_error":       cause.Error(),
+		})
+		return
+	}
+
+	if job.Kind == domain.JobKindReindex {
+		// The old chunks are only replaced on success, so they can still be served
+		doc, err := s.docRepo.GetByID(ctx, job.DocumentID)
+		stored, countErr := s.countActiveChunks(ctx, job.DocumentID)
+		if err == nil && countErr == nil && stored > 0 {
+			status := domain.StatusCompleted
+			if doc.SkippedChunks > 0 {
+				status = domain.StatusPartial
+			}
+			s.setProgress(ctx, job.DocumentID, map[string]interface{}{
+				"status":           status,
+				"processing_stage": domain.StageDone,
+				"eta":              nil,
+				"last_error":       "reindex failed: " + cause.Error(),
+			})
+			return
+		}
+	}
+
+	s.failDocument(ctx, job.DocumentID, cause)
+}
+
+// processPDF parses the stored PDF with the given settings, embeds its chunks
+// in the active embedding space and replaces the document's chunks with them
+func (s *DocumentService) processPDF(ctx context.Context, docID string, settings domain.IndexSettings) error {
 	fmt.Printf("\n=== PROCESS PDF START (ID: %s) ===\n", docID)
-	fmt.Printf("File: %s, Size: %d bytes (%.2f MB)\n", filename, len(fileContent), float64(len(fileContent))/(1024*1024))
 
-	// Create a context with timeout for large PDFs (10 minutes)
-	pdfCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
+	doc, err := s.docRepo.GetByID(ctx, docID)
+	if err != nil {
+		return permanent(domain.ErrCodeInternal, fmt.Errorf("failed to get document %s: %w", docID, err))
+	}
+
+	space, err := s.spaceRepo.GetActive(ctx)
+	if err != nil {
+		return failure(domain.ErrCodeInternal, fmt.Errorf("failed to get active embedding space: %w", err))
+	}
+	settings.EmbeddingModel = space.Model
+
+	s.setProgress(ctx, docID, map[string]interface{}{
+		"processing_stage": domain.StageParsing,
+		"stage_started_at": time.Now(),
+		"chunks_done":      0,
+		"chunks_total":     0,
+		"eta":              nil,
+	})
+
+	parsed, totalPages, err := s.parseChunks(ctx, doc, settings, space.ID)
+	if err != nil {
+		return err
+	}
+
+	// Update total pages and start the embedding stage
+	s.setProgress(ctx, docID, map[string]interface{}{
+		"total_pages":      totalPages,
+		"processing_stage": domain.StageEmbedding,
+		"stage_started_at": time.Now(),
+		"chunks_total":     len(parsed),
+	})
+
+	// Generate embeddings
+	chunks, failed, err := s.embedParsedChunks(ctx, docID, space, parsed, true)
+	if err != nil {
+		return err
+	}
+
+	// Check if we have any chunks
+	if len(chunks) == 0 {
+		fmt.Printf("ERROR: No chunks created for document %s (all embeddings failed)\n", docID)
+		return failure(domain.ErrCodeEmbeddingFailed, fmt.Errorf("all %d chunk embeddings failed: %s", len(failed), failed[0].Error))
+	}
+
+	// Swap in the new chunks; queries see either the old or the new set
+	s.setProgress(ctx, docID, map[string]interface{}{
+		"processing_stage": domain.StageStoring,
+		"stage_started_at": time.Now(),
+		"eta":              nil,
+	})
+	if err := s.chunkRepo.ReplaceForDocument(ctx, docID, space.ID, chunks, failed); err != nil {
+		fmt.Printf("ERROR: Failed to save chunks for document %s: %v\n", docID, err)
+		return failure(domain.ErrCodeStorageFailed, fmt.Errorf("failed to save chunks: %w", err))
+	}
+
+	fmt.Printf("SUCCESS: Saved %d chunks for document %s (%d skipped)\n", len(chunks), docID, len(failed))
+	s.finishDocument(ctx, docID, len(chunks), failed, &settings)
+	s.queueBuildingSpaces(ctx, docID)
+	return nil
+}
+
+// embedIntoSpace stores a document's chunks in a building embedding space,
+// chunked with the document's current settings. The document's status and
+// the chunks it is served from are not touched.
+func (s *DocumentService) embedIntoSpace(ctx context.Context, docID, spaceID string) error {
+	fmt.Printf("\n=== EMBED INTO SPACE START (ID: %s, space: %s) ===\n", docID, spaceID)
+
+	doc, err := s.docRepo.GetByID(ctx, docID)
+	if err != nil {
+		return permanent(domain.ErrCodeInternal, fmt.Errorf("failed to get document %s: %w", docID, err))
+	}
+	space, err := s.spaceRepo.GetByID(ctx, spaceID)
+	if err != nil {
+		return permanent(domain.ErrCodeInternal, fmt.Errorf("failed to get embedding space %s: %w", spaceID, err))
+	}
+
+	parsed, _, err := s.parseChunks(ctx, doc, s.withDefaults(doc.IndexSettings), space.ID)
+	if err != nil {
+		return err
+	}
+
+	chunks, failed, err := s.embedParsedChunks(ctx, docID, space, parsed, false)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return failure(domain.ErrCodeEmbeddingFailed, fmt.Errorf("all %d chunk embeddings failed: %s", len(failed), failed[0].Error))
+	}
+
+	if err := s.chunkRepo.ReplaceForDocument(ctx, docID, space.ID, chunks, failed); err != nil {
+		return failure(domain.ErrCodeStorageFailed, fmt.Errorf("failed to save chunks: %w", err))
+	}
+
+	fmt.Printf("SUCCESS: Saved %d chunks for document %s in embedding space %s (%d skipped)\n", len(chunks), docID, space.ID, len(failed))
+	return nil
+}
+
+// parseChunks reads the document's file and splits it into chunks with the
+// given settings. It returns the chunks, without embeddings, and the page
+// count.
+func (s *DocumentService) parseChunks(ctx context.Context, doc *domain.Document, settings domain.IndexSettings, spaceID string) ([]*domain.Chunk, int, error) {
+	fileContent, err := os.ReadFile(doc.FilePath)
+	if err != nil {
+		return nil, 0, permanent(domain.ErrCodeFileMissing, fmt.Errorf("failed to read %s: %w", doc.FilePath, err))
+	}
+	fmt.Printf("File: %s, Size: %d bytes (%.2f MB)\n", doc.Filename, len(fileContent), float64(len(fileContent))/(1024*1024))
 
 	// Call docreader to parse PDF
 	fmt.Println("Calling docreader gRPC service...")
 	startTime := time.Now()
-	resp, err := s.docreaderClient.ParsePDF(pdfCtx, fileContent, filename, 500, 50)
+	fmt.Printf("Chunk size: %d, overlap: %d\n", settings.ChunkSize, settings.ChunkOverlap)
+	resp, err := s.docreaderClient.ParsePDF(ctx, fileContent, doc.Filename, int32(settings.ChunkSize), int32(settings.ChunkOverlap))
 	duration := time.Since(startTime)
 
 	if err != nil {
 		fmt.Printf("ERROR: Docreader ParsePDF failed after %v: %v\n", duration, err)
-		s.updateDocumentStatus(ctx, docID, "error")
-		return
+		return nil, 0, failure(domain.ErrCodeDocreaderUnavailable, fmt.Errorf("docreader ParsePDF failed: %w", err))
 	}
 	fmt.Printf("Docreader response received in %v. Total pages: %d, Chunks: %d\n", duration, resp.TotalPages, len(resp.Chunks))
 
 	if resp.Error != "" {
 		fmt.Printf("ERROR: Docreader returned error: %s\n", resp.Error)
-		s.updateDocumentStatus(ctx, docID, "error")
-		return
+		return nil, 0, permanent(domain.ErrCodeParseFailed, fmt.Errorf("docreader error: %s", resp.Error))
+	}
+
+	if len(resp.Chunks) == 0 {
+		return nil, 0, permanent(domain.ErrCodeNoContent, fmt.Errorf("no text could be extracted from the PDF"))
+	}
+
+	parsed := make([]*domain.Chunk, 0, len(resp.Chunks))
+	for _, pbChunk := range resp.Chunks {
+		chunk := newChunk(doc.ID, pbChunk)
+		chunk.EmbeddingSpaceID = spaceID
+		chunk.WorkspaceID = doc.WorkspaceID
+		parsed = append(parsed, chunk)
 	}
 
-	// Update total pages
+	return parsed, int(resp.TotalPages), nil
+}
+
+// retryFailedChunks embeds the chunks that failed during processing again
+// and stores the ones that now succeed.
+func (s *DocumentService) retryFailedChunks(ctx context.Context, docID string) error {
+	fmt.Printf("\n=== RETRY FAILED CHUNKS START (ID: %s) ===\n", docID)
+
 	doc, err := s.docRepo.GetByID(ctx, docID)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to get document %s: %v\n", docID, err)
-		s.updateDocumentStatus(ctx, docID, "error")
-		return
+		return permanent(domain.ErrCodeInternal, fmt.Errorf("failed to get document %s: %w", docID, err))
 	}
-	doc.TotalPages = int(resp.TotalPages)
-	if err := s.docRepo.Update(ctx, doc); err != nil {
-		fmt.Printf("ERROR: Failed to update document %s: %v\n", docID, err)
+
+	space, err := s.spaceRepo.GetActive(ctx)
+	if err != nil {
+		return failure(domain.ErrCodeInternal, fmt.Errorf("failed to get active embedding space: %w", err))
 	}
 
-	// Process chunks
-	chunks := make([]*domain.Chunk, 0, len(resp.Chunks))
-	totalChunks := len(resp.Chunks)
-	fmt.Printf("Generating embeddings for %d chunks...\n", totalChunks)
+	previous, err := s.chunkRepo.GetFailedByDocumentID(ctx, docID, space.ID)
+	if err != nil {
+		return failure(domain.ErrCodeInternal, fmt.Errorf("failed to load failed chunks: %w", err))
+	}
 
-	for i, pbChunk := range resp.Chunks {
-		// Progress logging every 100 chunks
-		if i%100 == 0 {
-			fmt.Printf("Progress: %d/%d chunks (%.1f%%)\n", i, totalChunks, float64(i)*100/float64(totalChunks))
-		}
+	stored, err := s.chunkRepo.CountByDocumentID(ctx, docID, space.ID)
+	if err != nil {
+		return failure(domain.ErrCodeInternal, fmt.Errorf("failed to count chunks: %w", err))
+	}
 
-		// Generate embedding
-		embedding, err := s.llmClient.GetEmbedding(pbChunk.Content, s.config.Embedding.Model)
-		if err != nil {
-			fmt.Printf("WARNING: Failed to generate embedding for chunk %d in document %s: %v\n", pbChunk.ChunkIndex, docID, err)
+	s.setProgress(ctx, docID, map[string]interface{}{
+		"processing_stage": domain.StageEmbedding,
+		"stage_started_at": time.Now(),
+		"chunks_done":      0,
+		"chunks_total":     len(previous),
+	})
+
+	parsed := make([]*domain.Chunk, 0, len(previous))
+	attempts := make(map[string]int, len(previous))
+	for _, failedChunk := range previous {
+		chunk := failedChunk.ToChunk()
+		chunk.WorkspaceID = doc.WorkspaceID
+		parsed = append(parsed, chunk)
+		attempts[failedChunk.ID] = failedChunk.Attempts
+	}
+
+	recovered, stillFailed, err := s.embedParsedChunks(ctx, docID, space, parsed, true)
+	if err != nil {
+		return err
+	}
+	for _, failedChunk := range stillFailed {
+		failedChunk.Attempts = attempts[failedChunk.ID] + 1
+	}
+
+	s.setProgress(ctx, docID, map[string]interface{}{
+		"processing_stage": domain.StageStoring,
+		"stage_started_at": time.Now(),
+		"eta":              nil,
+	})
+	if err := s.chunkRepo.ResolveFailed(ctx, docID, space.ID, recovered, stillFailed); err != nil {
+		return failure(domain.ErrCodeStorageFailed, fmt.Errorf("failed to save recovered chunks: %w", err))
+	}
+
+	fmt.Printf("SUCCESS: Recovered %d of %d failed chunks for document %s\n", len(recovered), len(previous), docID)
+	s.finishDocument(ctx, docID, int(stored)+len(recovered), stillFailed, nil)
+	return nil
+}
+
+// embedParsedChunks embeds parsed chunks in space, splitting them into
+// embedded chunks and chunks whose embedding failed.
+func (s *DocumentService) embedParsedChunks(ctx context.Context, docID string, space *domain.EmbeddingSpace, parsed []*domain.Chunk, reportProgress bool) ([]*domain.Chunk, []*domain.FailedChunk, error) {
+	texts := make([]string, len(parsed))
+	for i, chunk := range parsed {
+		texts[i] = chunk.Content
+	}
+
+	fmt.Printf("Generating embeddings for %d chunks...\n", len(parsed))
+	embeddingStart := time.Now()
+	embeddings, errs, err := s.embedChunks(ctx, docID, space, texts, reportProgress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chunks := make([]*domain.Chunk, 0, len(parsed))
+	var failed []*domain.FailedChunk
+	for i, chunk := range parsed {
+		if errs[i] != nil {
+			failed = append(failed, domain.NewFailedChunk(chunk, errs[i]))
 			continue
 		}
 
 		// Convert float64 to float32 for pgvector
-		embedding32 := make([]float32, len(embedding))
-		for i, v := range embedding {
-			embedding32[i] = float32(v)
+		embedding32 := make([]float32, len(embeddings[i]))
+		for j, v := range embeddings[i] {
+			embedding32[j] = float32(v)
 		}
+		chunk.Embedding = pgvector.NewVector(embedding32)
+		chunks = append(chunks, chunk)
+	}
+	fmt.Printf("Embeddings generated in %v (%d failed)\n", time.Since(embeddingStart), len(failed))
 
-		chunk := &domain.Chunk{
-			ID:         uuid.New().String(),
-			DocumentID: docID,
-			Content:    pbChunk.Content,
-			ChunkIndex: int(pbChunk.ChunkIndex),
-			PageNumber: int(pbChunk.PageNumber),
-			StartPos:   int(pbChunk.StartPos),
-			EndPos:     int(pbChunk.EndPos),
-			Embedding:  pgvector.NewVector(embedding32),
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-		}
+	return chunks, failed, nil
+}
 
-		// Add bbox if present
-		if pbChunk.Bbox != nil {
-			x1, y1, x2, y2 := float64(pbChunk.Bbox.X1), float64(pbChunk.Bbox.Y1), float64(pbChunk.Bbox.X2), float64(pbChunk.Bbox.Y2)
-			chunk.BboxX1 = &x1
-			chunk.BboxY1 = &y1
-			chunk.BboxX2 = &x2
-			chunk.BboxY2 = &y2
-		}
+// newChunk converts a chunk returned by docreader into a domain chunk
+// without an embedding
+func newChunk(docID string, pbChunk *pb.Chunk) *domain.Chunk {
+	chunk := &domain.Chunk{
+		ID:         uuid.New().String(),
+		DocumentID: docID,
+		Content:    pbChunk.Content,
+		ChunkIndex: int(pbChunk.ChunkIndex),
+		PageNumber: int(pbChunk.PageNumber),
+		StartPos:   int(pbChunk.StartPos),
+		EndPos:     int(pbChunk.EndPos),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
 
-		chunks = append(chunks, chunk)
+	// Add bbox if present
+	if pbChunk.Bbox != nil {
+		x1, y1, x2, y2 := float64(pbChunk.Bbox.X1), float64(pbChunk.Bbox.Y1), float64(pbChunk.Bbox.X2), float64(pbChunk.Bbox.Y2)
+		chunk.BboxX1 = &x1
+		chunk.BboxY1 = &y1
+		chunk.BboxX2 = &x2
+		chunk.BboxY2 = &y2
 	}
 
-	// Check if we have any chunks
-	if len(chunks) == 0 {
-		fmt.Printf("ERROR: No chunks created for document %s (all embeddings failed)\n", docID)
-		s.updateDocumentStatus(ctx, docID, "error")
-		return
+	return chunk
+}
+
+// finishDocument sets the final status once chunks are stored: completed when
+// every chunk was embedded, partial when some had to be skipped. settings, if
+// not nil, are recorded as the settings the chunks were built with.
+func (s *DocumentService) finishDocument(ctx context.Context, docID string, stored int, failed []*domain.FailedChunk, settings *domain.IndexSettings) {
+	fields := map[string]interface{}{
+		"status":           domain.StatusCompleted,
+		"processing_stage": domain.StageDone,
+		"stage_started_at": time.Now(),
+		"eta":              nil,
+		"last_error":       "",
+		"error_code":       "",
+		"error_message":    "",
+		"skipped_chunks":   len(failed),
 	}
 
-	// Save chunks
-	if err := s.chunkRepo.BatchCreate(ctx, chunks); err != nil {
-		fmt.Printf("ERROR: Failed to save chunks for document %s: %v\n", docID, err)
-		s.updateDocumentStatus(ctx, docID, "error")
+	if len(failed) > 0 {
+		fields["status"] = domain.StatusPartial
+		fields["error_code"] = domain.ErrCodeEmbeddingFailed
+		fields["error_message"] = fmt.Sprintf("%d of %d chunks could not be embedded and were skipped: %s",
+			len(failed), stored+len(failed), failed[0].Error)
+	}
+
+	if settings != nil {
+		fields["chunk_size"] = settings.ChunkSize
+		fields["chunk_overlap"] = settings.ChunkOverlap
+		fields["embedding_model"] = settings.EmbeddingModel
+	}
+
+	s.setProgress(ctx, docID, fields)
+}
+
+// setProgress records ingestion progress on the document. Failures are only
+// logged: progress reporting must not break processing.
+func (s *DocumentService) setProgress(ctx context.Context, docID string, fields map[string]interface{}) {
+	if err := s.docRepo.UpdateFields(ctx, docID, fields); err != nil {
+		fmt.Printf("WARNING: Failed to update progress of document %s: %v\n", docID, err)
+	}
+}
+
+// recordRetry notes a failed attempt that will be retried
+func (s *DocumentService) recordRetry(ctx context.Context, job *domain.IngestionJob, cause error) {
+	if job.Kind == domain.JobKindEmbedSpace {
+		// Building another space does not change the document's progress
 		return
 	}
+	s.setProgress(ctx, job.DocumentID, map[string]interface{}{
+		"processing_stage": domain.StageQueued,
+		"eta":              nil,
+		"last_error":       cause.Error(),
+	})
+}
 
-	// Update status to completed
-	fmt.Printf("SUCCESS: Saved %d chunks for document %s\n", len(chunks), docID)
-	s.updateDocumentStatus(ctx, docID, "completed")
+// failDocument marks the document as failed for good, keeping the reason
+func (s *DocumentService) failDocument(ctx context.Context, docID string, cause error) {
+	s.setProgress(ctx, docID, map[string]interface{}{
+		"status":        domain.StatusError,
+		"eta":           nil,
+		"last_error":    cause.Error(),
+		"error_code":    errorCode(cause),
+		"error_message": cause.Error(),
+	})
 }
 
-func (s *DocumentService) updateDocumentStatus(ctx context.Context, docID, status string) {
-	doc, err := s.docRepo.GetByID(ctx, docID)
+// FailedChunks returns the chunks of a document whose embedding failed
+func (s *DocumentService) FailedChunks(ctx context.Context, workspaceID, id string) ([]*domain.FailedChunk, error) {
+	doc, err := s.chunkSource(ctx, workspaceID, id)
 	if err != nil {
-		return
+		return nil, err
+	}
+
+	space, err := s.spaceRepo.GetActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.chunkRepo.GetFailedByDocumentID(ctx, doc.ID, space.ID)
+}
+
+// countActiveChunks counts the document's chunks in the active embedding space
+func (s *DocumentService) countActiveChunks(ctx context.Context, docID string) (int64, error) {
+	space, err := s.spaceRepo.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.chunkRepo.CountByDocumentID(ctx, docID, space.ID)
+}
+
+// chunkSource returns the document owning the chunks of document id, which
+// is the document itself unless it is an alias
+func (s *DocumentService) chunkSource(ctx context.Context, workspaceID, id string) (*domain.Document, error) {
+	doc, err := s.docRepo.GetInWorkspace(ctx, workspaceID, id)
+	if err == nil && doc.AliasOf != nil {
+		doc, err = s.docRepo.GetInWorkspace(ctx, workspaceID, *doc.AliasOf)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	return doc, err
+}
+
+// RetryFailedChunks queues a job that embeds only the chunks that failed
+// during processing. The document must be in the partial state.
+func (s *DocumentService) RetryFailedChunks(ctx context.Context, workspaceID, id string) (*domain.Document, error) {
+	doc, err := s.chunkSource(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Status != domain.StatusPartial {
+		return nil, ErrNoFailedChunks
+	}
+
+	if err := s.enqueueKind(ctx, doc.ID, domain.JobKindRetryFailed, doc.IndexSettings); err != nil {
+		return nil, fmt.Errorf("failed to queue retry: %w", err)
+	}
+
+	fields := map[string]interface{}{
+		"status":           domain.StatusProcessing,
+		"processing_stage": domain.StageQueued,
+		"eta":              nil,
 	}
-	doc.Status = status
-	s.docRepo.Update(ctx, doc)
+	if err := s.docRepo.UpdateFields(ctx, doc.ID, fields); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, workspaceID, id)
 }
 
-func (s *DocumentService) List(ctx context.Context) ([]*domain.Document, error) {
-	return s.docRepo.List(ctx)
+// GetProgress returns the document's ingestion progress
+func (s *DocumentService) GetProgress(ctx context.Context, workspaceID, id string) (*domain.DocumentProgress, error) {
+	doc, err := s.Get(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Progress(), nil
 }
 
-func (s *DocumentService) Get(ctx context.Context, id string) (*domain.Document, error) {
-	return s.docRepo.GetByID(ctx, id)
+// ReindexRequest selects the settings to rebuild chunks with. Unset fields
+// fall back to the configured defaults. The embedding model is that of the
+// active embedding space; naming another model is rejected.
+type ReindexRequest struct {
+	ChunkSize      *int   `json:"chunk_size"`
+	ChunkOverlap   *int   `json:"chunk_overlap"`
+	EmbeddingModel string `json:"embedding_model"`
 }
 
-func (s *DocumentService) Delete(ctx context.Context, id string) error {
-	// Delete chunks first
+// Reindex queues a job that parses and embeds the stored file again with the
+// requested settings. The current chunks stay searchable until the new ones
+// are swapped in.
+func (s *DocumentService) Reindex(ctx context.Context, workspaceID, id string, req ReindexRequest) (*domain.Document, error) {
+	settings, err := s.indexSettings(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.chunkSource(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reindex(ctx, doc, settings); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, workspaceID, id)
+}
+
+// ReindexAll queues a reindex job for every document of the workspace that
+// is not being processed. It returns how many documents were queued and
+// skipped.
+func (s *DocumentService) ReindexAll(ctx context.Context, workspaceID string, req ReindexRequest) (queued, skipped int, err error) {
+	settings, err := s.indexSettings(ctx, req)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	docs, err := s.docRepo.List(ctx, workspaceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, doc := range docs {
+		if doc.AliasOf != nil {
+			// Reindexed along with the document it duplicates
+			continue
+		}
+		err := s.reindex(ctx, doc, settings)
+		if errors.Is(err, ErrDocumentBusy) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return queued, skipped, err
+		}
+		queued++
+	}
+
+	fmt.Printf("Queued reindex of %d documents (%d skipped while processing)\n", queued, skipped)
+	return queued, skipped, nil
+}
+
+func (s *DocumentService) reindex(ctx context.Context, doc *domain.Document, settings domain.IndexSettings) error {
+	if doc.Status == domain.StatusProcessing {
+		return ErrDocumentBusy
+	}
+
+	if err := s.enqueueKind(ctx, doc.ID, domain.JobKindReindex, settings); err != nil {
+		return fmt.Errorf("failed to queue reindex: %w", err)
+	}
+
+	return s.docRepo.UpdateFields(ctx, doc.ID, map[string]interface{}{
+		"status":           domain.StatusProcessing,
+		"processing_stage": domain.StageQueued,
+		"eta":              nil,
+		"last_error":       "",
+	})
+}
+
+// indexSettings resolves a reindex request against the defaults and checks
+// that the chunking is usable
+func (s *DocumentService) indexSettings(ctx context.Context, req ReindexRequest) (domain.IndexSettings, error) {
+	settings := s.defaultIndexSettings()
+	if req.ChunkSize != nil {
+		settings.ChunkSize = *req.ChunkSize
+	}
+	if req.ChunkOverlap != nil {
+		settings.ChunkOverlap = *req.ChunkOverlap
+	}
+	if req.EmbeddingModel != "" {
+		space, err := s.spaceRepo.GetActive(ctx)
+		if err != nil {
+			return settings, fmt.Errorf("failed to get active embedding space: %w", err)
+		}
+		if req.EmbeddingModel != space.Model {
+			return settings, fmt.Errorf("%w: embedding model %s is not the active model %s; switch models through an embedding space",
+				ErrInvalidIndexSettings, req.EmbeddingModel, space.Model)
+		}
+	}
+
+	if settings.ChunkSize <= 0 {
+		return settings, fmt.Errorf("%w: chunk size must be positive", ErrInvalidIndexSettings)
+	}
+	if settings.ChunkOverlap < 0 || settings.ChunkOverlap >= settings.ChunkSize {
+		return settings, fmt.Errorf("%w: chunk overlap must be between 0 and chunk size", ErrInvalidIndexSettings)
+	}
+	return settings, nil
+}
+
+// Get returns a document of the workspace; documents of other workspaces
+// are reported as not found
+func (s *DocumentService) Get(ctx context.Context, workspaceID, id string) (*domain.Document, error) {
+	doc, err := s.docRepo.GetInWorkspace(ctx, workspaceID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	return doc, err
+}
+
+// Update edits the title, author, creation date, tags and metadata of a
+// document. An alias is edited on its own, not along with the document it
+// duplicates.
+func (s *DocumentService) Update(ctx context.Context, workspaceID, id string, req DocumentUpdate) (*domain.Document, error) {
+	doc, err := s.Get(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		doc.Title = strings.TrimSpace(*req.Title)
+		if utf8.RuneCountInString(doc.Title) > 512 {
+			return nil, fmt.Errorf("%w: title must be at most 512 characters", ErrInvalidDocumentUpdate)
+		}
+	}
+	if req.Author != nil {
+		doc.Author = strings.TrimSpace(*req.Author)
+		if utf8.RuneCountInString(doc.Author) > 255 {
+			return nil, fmt.Errorf("%w: author must be at most 255 characters", ErrInvalidDocumentUpdate)
+		}
+	}
+	if req.CreationDate != nil {
+		doc.CreationDate = nil
+		if *req.CreationDate != "" {
+			date, err := time.Parse(domain.DateLayout, *req.CreationDate)
+			if err != nil {
+				return nil, fmt.Errorf("%w: creation_date must be YYYY-MM-DD", ErrInvalidDocumentUpdate)
+			}
+			doc.CreationDate = &date
+		}
+	}
+	if req.Tags != nil {
+		doc.Tags = domain.NormalizeTags(*req.Tags)
+	}
+	if req.Metadata != nil {
+		metadata := make(map[string]string, len(doc.Metadata)+len(req.Metadata))
+		for key, value := range doc.Metadata {
+			metadata[key] = value
+		}
+		for key, value := range req.Metadata {
+			if value == nil {
+				delete(metadata, key)
+			} else {
+				metadata[key] = *value
+			}
+		}
+		if err := validateMetadata(metadata); err != nil {
+			return nil, err
+		}
+		doc.Metadata = metadata
+	}
+
+	if err := s.docRepo.UpdateMetadata(ctx, doc); err != nil {
+		return nil, fmt.Errorf("failed to update document: %w", err)
+	}
+	return s.Get(ctx, workspaceID, id)
+}
+
+// validateMetadata rejects empty keys
+func validateMetadata(metadata map[string]string) error {
+	for key := range metadata {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%w: metadata keys must not be empty", ErrInvalidDocumentUpdate)
+		}
+	}
+	return nil
+}
+
+func (s *DocumentService) Delete(ctx context.Context, workspaceID, id string) error {
+	doc, err := s.Get(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+
+	// An alias owns no chunks or jobs
+	if doc.AliasOf != nil {
+		return s.docRepo.Delete(ctx, id)
+	}
+
+	// Hand the chunks over to an alias, if any, instead of deleting them
+	aliases, err := s.docRepo.ListAliases(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(aliases) > 0 {
+		return s.docRepo.DeletePromotingAlias(ctx, id, aliases[0].ID)
+	}
+
+	// Delete jobs and chunks first
+	if err := s.jobRepo.DeleteByDocumentID(ctx, id); err != nil {
+		return err
+	}
 	if err := s.chunkRepo.DeleteByDocumentID(ctx, id); err != nil {
 		return err
 	}
@@ -242,9 +1065,9 @@ func (s *DocumentService) Delete(ctx context.Context, id string) error {
 	return s.docRepo.Delete(ctx, id)
 }
 
-func (s *DocumentService) RenderPageImage(ctx context.Context, docID, pageNum, bboxX1, bboxY1, bboxX2, bboxY2 string) ([]byte, error) {
+func (s *DocumentService) RenderPageImage(ctx context.Context, workspaceID, docID, pageNum, bboxX1, bboxY1, bboxX2, bboxY2 string) ([]byte, error) {
 	// Get document
-	doc, err := s.docRepo.GetByID(ctx, docID)
+	doc, err := s.Get(ctx, workspaceID, docID)
 	if err != nil {
 		return nil, fmt.Errorf("document not found: %w", err)
 	}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+)
+
+const (
+	defaultDocumentPageSize = 50
+	maxDocumentPageSize     = 200
+
+	// cursorTimeLayout keeps upload times to the microsecond, as stored
+	cursorTimeLayout = "2006-01-02 15:04:05.999999"
+)
+
+// DocumentListRequest selects a page of the workspace's documents. Dates are
+// YYYY-MM-DD and bound the upload day inclusively.
+type DocumentListRequest struct {
+	Status string `form:"status"`
+	// Filename matches a case-insensitive substring
+	Filename string `form:"filename"`
+	// Tags matches documents with any of the tags
+	Tags           []string `form:"tag"`
+	MinSize        *int64   `form:"min_size"`
+	MaxSize        *int64   `form:"max_size"`
+	UploadedAfter  string   `form:"uploaded_after"`
+	UploadedBefore string   `form:"uploaded_before"`
+
+	// Sort is upload_time (default), filename or file_size; Order is asc or
+	// desc, descending for upload time and ascending otherwise by default
+	Sort  string `form:"sort"`
+	Order string `form:"order"`
+	// Cursor is the next_cursor of the previous page
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// DocumentPage is one page of a document listing
+type DocumentPage struct {
+	Documents []*domain.Document `json:"documents"`
+	// Total counts all documents matching the filters, across pages
+	Total int64 `json:"total"`
+	// NextCursor continues the listing; empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// documentCursor is the opaque cursor handed to clients. It records the sort
+// it was made for, so it cannot be replayed against a different order.
+type documentCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// List returns a page of the workspace's documents matching the request
+func (s *DocumentService) List(ctx context.Context, workspaceID string, req DocumentListRequest) (*DocumentPage, error) {
+	query, err := documentQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.docRepo.Count(ctx, workspaceID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	// One extra row tells whether there is another page
+	limit := query.Limit
+	query.Limit++
+	docs, err := s.docRepo.ListPage(ctx, workspaceID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	page := &DocumentPage{Documents: docs, Total: total}
+	if len(docs) > limit {
+		page.Documents = docs[:limit]
+		page.NextCursor = encodeDocumentCursor(query, page.Documents[limit-1])
+	}
+	return page, nil
+}
+
+// documentQuery validates a list request and turns it into a repository
+// query
+func documentQuery(req DocumentListRequest) (repository.DocumentQuery, error) {
+	query := repository.DocumentQuery{
+		Status:         strings.TrimSpace(req.Status),
+		Filename:       req.Filename,
+		Tags:           req.Tags,
+		MinSize:        req.MinSize,
+		MaxSize:        req.MaxSize,
+		UploadedAfter:  strings.TrimSpace(req.UploadedAfter),
+		UploadedBefore: strings.TrimSpace(req.UploadedBefore),
+		Sort:           strings.TrimSpace(req.Sort),
+		Limit:          req.Limit,
+	}
+
+	switch query.Status {
+	case "", domain.StatusProcessing, domain.StatusCompleted, domain.StatusPartial, domain.StatusError:
+	default:
+		return query, fmt.Errorf("%w: unknown status %q", ErrInvalidDocumentQuery, query.Status)
+	}
+	if (query.MinSize != nil && *query.MinSize < 0) || (query.MaxSize != nil && *query.MaxSize < 0) {
+		return query, fmt.Errorf("%w: sizes must not be negative", ErrInvalidDocumentQuery)
+	}
+	if query.MinSize != nil && query.MaxSize != nil && *query.MinSize > *query.MaxSize {
+		return query, fmt.Errorf("%w: min_size is greater than max_size", ErrInvalidDocumentQuery)
+	}
+	for _, date := range []string{query.UploadedAfter, query.UploadedBefore} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(domain.DateLayout, date); err != nil {
+			return query, fmt.Errorf("%w: invalid date %q: dates must be YYYY-MM-DD", ErrInvalidDocumentQuery, date)
+		}
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = defaultDocumentPageSize
+	case query.Limit < 0 || query.Limit > maxDocumentPageSize:
+		return query, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidDocumentQuery, maxDocumentPageSize)
+	}
+
+	if query.Sort == "" {
+		query.Sort = domain.DocumentSortUploadTime
+	}
+	switch query.Sort {
+	case domain.DocumentSortUploadTime:
+		query.Descending = true
+	case domain.DocumentSortFilename, domain.DocumentSortFileSize:
+	default:
+		return query, fmt.Errorf("%w: unknown sort %q", ErrInvalidDocumentQuery, query.Sort)
+	}
+	switch strings.ToLower(strings.TrimSpace(req.Order)) {
+	case "":
+	case "asc":
+		query.Descending = false
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("%w: order must be asc or desc", ErrInvalidDocumentQuery)
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeDocumentCursor(query, req.Cursor)
+		if err != nil {
+			return query, err
+		}
+		query.After = after
+	}
+	return query, nil
+}
+
+func encodeDocumentCursor(query repository.DocumentQuery, doc *domain.Document) string {
+	cursor := documentCursor{Sort: query.Sort, Order: sortOrder(query), ID: doc.ID}
+	switch query.Sort {
+	case domain.DocumentSortFilename:
+		cursor.Value = doc.Filename
+	case domain.DocumentSortFileSize:
+		cursor.Value = strconv.FormatInt(doc.FileSize, 10)
+	default:
+		cursor.Value = doc.UploadTime.Format(cursorTimeLayout)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeDocumentCursor checks that a cursor was made for the query's sort
+// and holds a value of the sort field's type
+func decodeDocumentCursor(query repository.DocumentQuery, raw string) (*repository.DocumentCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidDocumentQuery)
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor documentCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, invalid
+	}
+	if cursor.Sort != query.Sort || cursor.Order != sortOrder(query) {
+		return nil, fmt.Errorf("%w: cursor was made for a different sort", ErrInvalidDocumentQuery)
+	}
+
+	switch query.Sort {
+	case domain.DocumentSortFileSize:
+		if _, err := strconv.ParseInt(cursor.Value, 10, 64); err != nil {
+			return nil, invalid
+		}
+	case domain.DocumentSortUploadTime:
+		if _, err := time.Parse(cursorTimeLayout, cursor.Value); err != nil {
+			return nil, invalid
+		}
+	}
+	return &repository.DocumentCursor{Value: cursor.Value, ID: cursor.ID}, nil
+}
+
+func sortOrder(query repository.DocumentQuery) string {
+	if query.Descending {
+		return "desc"
+	}
+	return "asc"
+}
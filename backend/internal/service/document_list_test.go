@@ -0,0 +1,113 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+)
+
+func TestDocumentCursorRoundTrip(t *testing.T) {
+	doc := &domain.Document{
+		ID:         "doc-1",
+		Filename:   "Report, final (v2).pdf",
+		FileSize:   1048576,
+		UploadTime: time.Date(2024, 3, 5, 10, 20, 30, 123456000, time.UTC),
+	}
+	values := map[string]string{
+		domain.DocumentSortUploadTime: "2024-03-05 10:20:30.123456",
+		domain.DocumentSortFilename:   "Report, final (v2).pdf",
+		domain.DocumentSortFileSize:   "1048576",
+	}
+
+	for sort, value := range values {
+		for _, order := range []string{"asc", "desc"} {
+			t.Run(sort+" "+order, func(t *testing.T) {
+				query, err := documentQuery(DocumentListRequest{Sort: sort, Order: order})
+				if err != nil {
+					t.Fatalf("documentQuery failed: %v", err)
+				}
+
+				next, err := documentQuery(DocumentListRequest{Sort: sort, Order: order, Cursor: encodeDocumentCursor(query, doc)})
+				if err != nil {
+					t.Fatalf("cursor rejected: %v", err)
+				}
+				want := &repository.DocumentCursor{Value: value, ID: doc.ID}
+				if !reflect.DeepEqual(next.After, want) {
+					t.Errorf("After = %+v, want %+v", next.After, want)
+				}
+				if next.Descending != (order == "desc") {
+					t.Errorf("Descending = %v for order %s", next.Descending, order)
+				}
+			})
+		}
+	}
+}
+
+func TestDocumentCursorRejected(t *testing.T) {
+	doc := &domain.Document{ID: "doc-1", Filename: "a.pdf", FileSize: 10, UploadTime: time.Now()}
+	cursor := func(req DocumentListRequest) string {
+		query, err := documentQuery(req)
+		if err != nil {
+			t.Fatalf("documentQuery failed: %v", err)
+		}
+		return encodeDocumentCursor(query, doc)
+	}
+	raw := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name string
+		req  DocumentListRequest
+	}{
+		{"different sort", DocumentListRequest{Sort: "filename", Cursor: cursor(DocumentListRequest{Sort: "file_size"})}},
+		{"different order", DocumentListRequest{Sort: "filename", Order: "desc", Cursor: cursor(DocumentListRequest{Sort: "filename"})}},
+		{"default sort with explicit ascending order", DocumentListRequest{Order: "asc", Cursor: cursor(DocumentListRequest{})}},
+		{"bad base64", DocumentListRequest{Cursor: "not a cursor!"}},
+		{"not json", DocumentListRequest{Cursor: raw("upload_time")}},
+		{"missing id", DocumentListRequest{Sort: "filename", Cursor: raw(`{"s":"filename","o":"asc","v":"a.pdf"}`)}},
+		{"non-numeric file size", DocumentListRequest{Sort: "file_size", Cursor: raw(`{"s":"file_size","o":"asc","v":"big","id":"doc-1"}`)}},
+		{"malformed upload time", DocumentListRequest{Cursor: raw(`{"s":"upload_time","o":"desc","v":"yesterday","id":"doc-1"}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := documentQuery(tt.req); !errors.Is(err, ErrInvalidDocumentQuery) {
+				t.Errorf("err = %v, want ErrInvalidDocumentQuery", err)
+			}
+		})
+	}
+}
+
+func TestDocumentQueryLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+		valid bool
+	}{
+		{0, defaultDocumentPageSize, true},
+		{1, 1, true},
+		{maxDocumentPageSize, maxDocumentPageSize, true},
+		{maxDocumentPageSize + 1, 0, false},
+		{-1, 0, false},
+	}
+
+	for _, tt := range tests {
+		query, err := documentQuery(DocumentListRequest{Limit: tt.limit})
+		switch {
+		case !tt.valid:
+			if !errors.Is(err, ErrInvalidDocumentQuery) {
+				t.Errorf("limit %d: err = %v, want ErrInvalidDocumentQuery", tt.limit, err)
+			}
+		case err != nil:
+			t.Errorf("limit %d: unexpected error: %v", tt.limit, err)
+		case query.Limit != tt.want:
+			t.Errorf("limit %d: Limit = %d, want %d", tt.limit, query.Limit, tt.want)
+		}
+	}
+}
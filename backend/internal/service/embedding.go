@@ -0,0 +1,279 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"github.com/pdf-rag-system/backend/pkg/config"
+	"github.com/pgvector/pgvector-go"
+)
+
+// EmbeddingService generates embeddings through a two-tier cache: an
+// in-process LRU and the embedding_cache table. Only texts missing from both
+// are sent to the embedding API. Entries are keyed by model version and the
+// SHA-256 of the text with whitespace collapsed, and that normalized text is
+// what gets embedded.
+type EmbeddingService struct {
+	embedder  client.Embedder
+	cacheRepo *repository.EmbeddingCacheRepository
+	memory    *lruCache
+	persist   bool
+
+	memoryHits int64
+	dbHits     int64
+	misses     int64
+}
+
+// EmbeddingCacheStats counts cache lookups since the server started
+type EmbeddingCacheStats struct {
+	MemoryHits int64 `json:"memory_hits"`
+	DBHits     int64 `json:"db_hits"`
+	Misses     int64 `json:"misses"`
+	// HitRate is the share of lookups served without calling the API
+	HitRate        float64 `json:"hit_rate"`
+	MemoryEntries  int     `json:"memory_entries"`
+	MemoryCapacity int     `json:"memory_capacity"`
+}
+
+func NewEmbeddingService(cacheRepo *repository.EmbeddingCacheRepository, embedder client.Embedder, cfg *config.Config) *EmbeddingService {
+	return &EmbeddingService{
+		embedder:  embedder,
+		cacheRepo: cacheRepo,
+		memory:    newLRUCache(cfg.Embedding.CacheSize),
+		persist:   cfg.Embedding.CachePersist,
+	}
+}
+
+// Embed returns one embedding per text, in order, from the space's model.
+// Cache failures are logged and treated as misses; an error is returned only
+// if the API call fails or returns vectors of the wrong dimension.
+func (s *EmbeddingService) Embed(ctx context.Context, space *domain.EmbeddingSpace, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	model := space.CacheKey()
+
+	// Positions of each text still to be found, by hash; equal texts share a lookup
+	pending := make(map[string][]int)
+	normalized := make(map[string]string)
+	var order []string
+	for i, text := range texts {
+		norm := normalizeEmbeddingText(text)
+		hash := hashText(norm)
+
+		if vector, ok := s.memory.get(model + ":" + hash); ok {
+			embeddings[i] = toFloat64(vector)
+			atomic.AddInt64(&s.memoryHits, 1)
+			continue
+		}
+
+		if _, seen := pending[hash]; !seen {
+			order = append(order, hash)
+			normalized[hash] = norm
+		}
+		pending[hash] = append(pending[hash], i)
+	}
+
+	if len(order) == 0 {
+		return embeddings, nil
+	}
+
+	if s.persist {
+		stored, err := s.cacheRepo.GetMany(ctx, model, order)
+		if err != nil {
+			fmt.Printf("WARNING: Embedding cache lookup failed: %v\n", err)
+		}
+		remaining := order[:0]
+		for _, hash := range order {
+			vector, ok := stored[hash]
+			if !ok {
+				remaining = append(remaining, hash)
+				continue
+			}
+			s.memory.put(model+":"+hash, vector)
+			for _, i := range pending[hash] {
+				embeddings[i] = toFloat64(vector)
+			}
+			atomic.AddInt64(&s.dbHits, 1)
+		}
+		order = remaining
+	}
+
+	if len(order) == 0 {
+		return embeddings, nil
+	}
+
+	missing := make([]string, len(order))
+	for j, hash := range order {
+		missing[j] = normalized[hash]
+	}
+	atomic.AddInt64(&s.misses, int64(len(order)))
+
+	vectors, err := s.embedder.Embed(ctx, missing, space.Model)
+	if err != nil {
+		return nil, err
+	}
+	for _, vector := range vectors {
+		if len(vector) != space.Dimension {
+			return nil, fmt.Errorf("model %s returned %d-dimensional embeddings, space %s expects %d",
+				space.Model, len(vector), space.ID, space.Dimension)
+		}
+	}
+
+	entries := make([]*domain.CachedEmbedding, 0, len(order))
+	for j, hash := range order {
+		vector := toFloat32(vectors[j])
+		s.memory.put(model+":"+hash, vector)
+		for _, i := range pending[hash] {
+			embeddings[i] = vectors[j]
+		}
+		entries = append(entries, &domain.CachedEmbedding{
+			Model:     model,
+			TextHash:  hash,
+			Embedding: pgvector.NewVector(vector),
+		})
+	}
+
+	if s.persist {
+		if err := s.cacheRepo.PutMany(ctx, entries); err != nil {
+			fmt.Printf("WARNING: Failed to store %d embeddings in cache: %v\n", len(entries), err)
+		}
+	}
+
+	return embeddings, nil
+}
+
+// EmbedOne returns the embedding of a single text
+func (s *EmbeddingService) EmbedOne(ctx context.Context, space *domain.EmbeddingSpace, text string) ([]float64, error) {
+	embeddings, err := s.Embed(ctx, space, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// Probe embeds a sample text with model, bypassing the cache, and returns
+// the dimension of the embeddings the live endpoint produces
+func (s *EmbeddingService) Probe(ctx context.Context, model string) (int, error) {
+	vectors, err := s.embedder.Embed(ctx, []string{"dimension probe"}, model)
+	if err != nil {
+		return 0, err
+	}
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return 0, fmt.Errorf("model %s returned no embedding", model)
+	}
+	return len(vectors[0]), nil
+}
+
+// Stats returns the cache hit and miss counts
+func (s *EmbeddingService) Stats() EmbeddingCacheStats {
+	stats := EmbeddingCacheStats{
+		MemoryHits:     atomic.LoadInt64(&s.memoryHits),
+		DBHits:         atomic.LoadInt64(&s.dbHits),
+		Misses:         atomic.LoadInt64(&s.misses),
+		MemoryEntries:  s.memory.size(),
+		MemoryCapacity: s.memory.capacity,
+	}
+	if total := stats.MemoryHits + stats.DBHits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.MemoryHits+stats.DBHits) / float64(total)
+	}
+	return stats
+}
+
+// normalizeEmbeddingText collapses runs of whitespace, which do not change
+// the meaning of a text but would change its hash
+func normalizeEmbeddingText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func toFloat32(vector []float64) []float32 {
+	out := make([]float32, len(vector))
+	for i, v := range vector {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+func toFloat64(vector []float32) []float64 {
+	out := make([]float64, len(vector))
+	for i, v := range vector {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// lruCache is a fixed-size, least-recently-used map of embeddings. A
+// capacity of zero disables it.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	vector []float32
+}
+
+func newLRUCache(capacity int) *lruCache {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).vector, true
+}
+
+func (c *lruCache) put(key string, vector []float32) {
+	if c.capacity == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, vector: vector})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
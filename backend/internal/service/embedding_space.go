@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"github.com/pdf-rag-system/backend/pkg/config"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSpaceNotFound is returned when an embedding space ID does not exist
+	ErrSpaceNotFound = errors.New("embedding space not found")
+	// ErrSpaceExists is returned when creating a space for a model and
+	// version that already has a space in use
+	ErrSpaceExists = errors.New("an embedding space for this model and version already exists")
+	// ErrSpaceActive is returned when an operation is not allowed on the
+	// active space
+	ErrSpaceActive = errors.New("embedding space is active")
+	// ErrSpaceRetired is returned when building a retired space
+	ErrSpaceRetired = errors.New("embedding space is retired; create a new one instead")
+	// ErrSpaceIncomplete is returned when activating a space that is missing
+	// documents
+	ErrSpaceIncomplete = errors.New("embedding space is incomplete")
+	// ErrDimensionMismatch is returned when the embedding endpoint produces
+	// vectors of another dimension than configured
+	ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+)
+
+// EmbeddingSpaceService manages the embedding spaces chunks are stored in.
+// Queries use the active space; a new model is introduced by creating a
+// space, building it alongside the active one and then activating it.
+type EmbeddingSpaceService struct {
+	spaceRepo       *repository.EmbeddingSpaceRepository
+	documentService *DocumentService
+	embeddings      *EmbeddingService
+	config          *config.Config
+}
+
+func NewEmbeddingSpaceService(
+	spaceRepo *repository.EmbeddingSpaceRepository,
+	documentService *DocumentService,
+	embeddings *EmbeddingService,
+	cfg *config.Config,
+) *EmbeddingSpaceService {
+	return &EmbeddingSpaceService{
+		spaceRepo:       spaceRepo,
+		documentService: documentService,
+		embeddings:      embeddings,
+		config:          cfg,
+	}
+}
+
+// EmbeddingSpaceInfo is an embedding space with how much of the corpus it holds
+type EmbeddingSpaceInfo struct {
+	*domain.EmbeddingSpace
+	Documents int64 `json:"documents"`
+	Chunks    int64 `json:"chunks"`
+}
+
+// CreateSpaceRequest describes a new embedding space. The model defaults to
+// EMBEDDING_MODEL and the dimension to what the model produces.
+type CreateSpaceRequest struct {
+	Model     string `json:"model"`
+	Version   string `json:"version"`
+	Dimension int    `json:"dimension"`
+}
+
+// Init checks the configured dimension against the live embedding endpoint
+// and makes sure an active space exists. On first start the active space is
+// created from the configuration and existing chunks are moved into it.
+func (s *EmbeddingSpaceService) Init(ctx context.Context) error {
+	cfg := s.config.Embedding
+
+	dimension, err := s.embeddings.Probe(ctx, cfg.Model)
+	if err != nil {
+		fmt.Printf("WARNING: Could not check the dimension of %s against the embedding endpoint: %v\n", cfg.Model, err)
+	} else if dimension != cfg.Dimension {
+		return fmt.Errorf("%w: %s produces %d-dimensional embeddings but EMBEDDING_DIMENSION is %d",
+			ErrDimensionMismatch, cfg.Model, dimension, cfg.Dimension)
+	}
+
+	active, err := s.spaceRepo.GetActive(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		active = &domain.EmbeddingSpace{
+			ID:        uuid.New().String(),
+			Model:     cfg.Model,
+			Version:   cfg.ModelVersion,
+			Dimension: cfg.Dimension,
+			Status:    domain.SpaceStatusActive,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := s.spaceRepo.Create(ctx, active); err != nil {
+			return fmt.Errorf("failed to create embedding space: %w", err)
+		}
+		if err := s.spaceRepo.AssignUnassigned(ctx, active.ID); err != nil {
+			return fmt.Errorf("failed to assign existing chunks to embedding space: %w", err)
+		}
+		fmt.Printf("Created active embedding space %s (%s, %d dimensions)\n", active.ID, active.CacheKey(), active.Dimension)
+	} else if err != nil {
+		return fmt.Errorf("failed to get active embedding space: %w", err)
+	}
+
+	if active.Model != cfg.Model || active.Version != cfg.ModelVersion || active.Dimension != cfg.Dimension {
+		fmt.Printf("WARNING: Queries use the active embedding space %s (%s, %d dimensions), not EMBEDDING_MODEL %s; activate another space to switch\n",
+			active.ID, active.CacheKey(), active.Dimension, cfg.Model)
+	}
+
+	// Index builds can take a while on a large corpus; don't hold up startup
+	spaces, err := s.spaceRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list embedding spaces: %w", err)
+	}
+	go func() {
+		for _, space := range spaces {
+			if space.Status == domain.SpaceStatusRetired {
+				continue
+			}
+			if err := s.spaceRepo.EnsureIndex(context.Background(), space); err != nil {
+				fmt.Printf("ERROR: Failed to create index for embedding space %s: %v\n", space.ID, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// List returns all embedding spaces with their coverage
+func (s *EmbeddingSpaceService) List(ctx context.Context) ([]*EmbeddingSpaceInfo, error) {
+	spaces, err := s.spaceRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	coverage, err := s.spaceRepo.Coverage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*EmbeddingSpaceInfo, 0, len(spaces))
+	for _, space := range spaces {
+		info := &EmbeddingSpaceInfo{EmbeddingSpace: space}
+		if c, ok := coverage[space.ID]; ok {
+			info.Documents = c.Documents
+			info.Chunks = c.Chunks
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Create adds a building embedding space after checking the model's
+// dimension against the live embedding endpoint
+func (s *EmbeddingSpaceService) Create(ctx context.Context, req CreateSpaceRequest) (*domain.EmbeddingSpace, error) {
+	if req.Model == "" {
+		req.Model = s.config.Embedding.Model
+	}
+
+	space := &domain.EmbeddingSpace{
+		ID:        uuid.New().String(),
+		Model:     req.Model,
+		Version:   req.Version,
+		Status:    domain.SpaceStatusBuilding,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	existing, err := s.spaceRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if other.Status != domain.SpaceStatusRetired && other.CacheKey() == space.CacheKey() {
+			return nil, ErrSpaceExists
+		}
+	}
+
+	dimension, err := s.embeddings.Probe(ctx, req.Model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach embedding endpoint for %s: %w", req.Model, err)
+	}
+	if req.Dimension != 0 && req.Dimension != dimension {
+		return nil, fmt.Errorf("%w: %s produces %d-dimensional embeddings, not %d",
+			ErrDimensionMismatch, req.Model, dimension, req.Dimension)
+	}
+	space.Dimension = dimension
+
+	if err := s.spaceRepo.Create(ctx, space); err != nil {
+		return nil, fmt.Errorf("failed to create embedding space: %w", err)
+	}
+	if err := s.spaceRepo.EnsureIndex(ctx, space); err != nil {
+		return nil, fmt.Errorf("failed to create index for embedding space: %w", err)
+	}
+
+	fmt.Printf("Created embedding space %s (%s, %d dimensions)\n", space.ID, space.CacheKey(), space.Dimension)
+	return space, nil
+}
+
+// Build queues every indexed document for embedding into a building space.
+// Documents indexed afterwards are added to building spaces automatically.
+func (s *EmbeddingSpaceService) Build(ctx context.Context, id string) (int, error) {
+	space, err := s.get(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	switch space.Status {
+	case domain.SpaceStatusActive:
+		return 0, ErrSpaceActive
+	case domain.SpaceStatusRetired:
+		return 0, ErrSpaceRetired
+	}
+
+	queued, err := s.documentService.QueueSpaceBuild(ctx, space)
+	fmt.Printf("Queued %d documents for embedding space %s\n", queued, space.ID)
+	return queued, err
+}
+
+// Activate switches queries to the space and retires the previously active
+// one. Unless force is set, every indexed document must already have chunks
+// in the space.
+func (s *EmbeddingSpaceService) Activate(ctx context.Context, id string, force bool) (*domain.EmbeddingSpace, error) {
+	space, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		missing, err := s.spaceRepo.CountMissingDocuments(ctx, space.ID)
+		if err != nil {
+			return nil, err
+		}
+		if missing > 0 {
+			return nil, fmt.Errorf("%w: %d documents have no chunks in it yet", ErrSpaceIncomplete, missing)
+		}
+	}
+
+	if err := s.spaceRepo.Activate(ctx, space); err != nil {
+		return nil, fmt.Errorf("failed to activate embedding space: %w", err)
+	}
+
+	fmt.Printf("Activated embedding space %s (%s)\n", space.ID, space.CacheKey())
+	return s.spaceRepo.GetByID(ctx, space.ID)
+}
+
+// Delete removes an inactive space together with its chunks and index
+func (s *EmbeddingSpaceService) Delete(ctx context.Context, id string) error {
+	space, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if space.Status == domain.SpaceStatusActive {
+		return ErrSpaceActive
+	}
+
+	if err := s.spaceRepo.DropIndex(ctx, space); err != nil {
+		return fmt.Errorf("failed to drop index of embedding space: %w", err)
+	}
+	return s.spaceRepo.Delete(ctx, space.ID)
+}
+
+func (s *EmbeddingSpaceService) get(ctx context.Context, id string) (*domain.EmbeddingSpace, error) {
+	space, err := s.spaceRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSpaceNotFound
+	}
+	return space, err
+}
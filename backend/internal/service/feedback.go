@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQueryNotFound is returned when no recorded query has the given ID
+	ErrQueryNotFound = errors.New("query not found")
+	// ErrFeedbackNotFound is returned when a query has no feedback yet
+	ErrFeedbackNotFound = errors.New("feedback not found")
+	// ErrInvalidFeedback is returned for feedback that cannot be stored
+	ErrInvalidFeedback = errors.New("invalid feedback")
+)
+
+// FeedbackService stores user judgements of query results and exports them
+// as labeled data for tuning retrieval
+type FeedbackService struct {
+	historyRepo  *repository.QueryHistoryRepository
+	feedbackRepo *repository.FeedbackRepository
+}
+
+func NewFeedbackService(historyRepo *repository.QueryHistoryRepository, feedbackRepo *repository.FeedbackRepository) *FeedbackService {
+	return &FeedbackService{
+		historyRepo:  historyRepo,
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+type FeedbackRequest struct {
+	// Rating is "up", "down" or empty
+	Rating    string                `json:"rating"`
+	Comment   string                `json:"comment"`
+	Citations []domain.CitationMark `json:"citations"`
+}
+
+// Submit stores feedback on a query recorded in the workspace, replacing
+// earlier feedback. Citation marks must refer to chunks cited in the query's
+// answer.
+func (s *FeedbackService) Submit(ctx context.Context, workspaceID string, queryID int64, req FeedbackRequest) (*domain.QueryFeedback, error) {
+	entry, err := s.historyRepo.GetByID(ctx, workspaceID, queryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQueryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query: %w", err)
+	}
+
+	rating := strings.ToLower(strings.TrimSpace(req.Rating))
+	if rating != "" && rating != domain.RatingUp && rating != domain.RatingDown {
+		return nil, fmt.Errorf("%w: rating must be up or down", ErrInvalidFeedback)
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if rating == "" && comment == "" && len(req.Citations) == 0 {
+		return nil, fmt.Errorf("%w: rating, comment or citations required", ErrInvalidFeedback)
+	}
+
+	cited := make(map[string]bool, len(entry.Citations))
+	for _, citation := range entry.Citations {
+		cited[citation.ChunkID] = true
+	}
+
+	marks := make(domain.CitationMarks, 0, len(req.Citations))
+	marked := make(map[string]bool, len(req.Citations))
+	for _, mark := range req.Citations {
+		if !cited[mark.ChunkID] {
+			return nil, fmt.Errorf("%w: chunk %s was not cited in this answer", ErrInvalidFeedback, mark.ChunkID)
+		}
+		if marked[mark.ChunkID] {
+			return nil, fmt.Errorf("%w: chunk %s is marked more than once", ErrInvalidFeedback, mark.ChunkID)
+		}
+		marked[mark.ChunkID] = true
+		marks = append(marks, mark)
+	}
+
+	feedback := &domain.QueryFeedback{
+		ID:        uuid.New().String(),
+		QueryID:   queryID,
+		Rating:    rating,
+		Comment:   comment,
+		Citations: marks,
+	}
+	if err := s.feedbackRepo.Upsert(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	// Re-read so a replaced feedback keeps its original ID and timestamps
+	return s.Get(ctx, workspaceID, queryID)
+}
+
+// Get returns the feedback on a query recorded in the workspace
+func (s *FeedbackService) Get(ctx context.Context, workspaceID string, queryID int64) (*domain.QueryFeedback, error) {
+	_, err := s.historyRepo.GetByID(ctx, workspaceID, queryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQueryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query: %w", err)
+	}
+
+	feedback, err := s.feedbackRepo.GetByQueryID(ctx, queryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// Export calls fn for every labeled (query, chunk, relevant) example from
+// feedback on the workspace's queries given since the given time
+func (s *FeedbackService) Export(ctx context.Context, workspaceID string, since time.Time, fn func(*domain.LabeledCitation) error) error {
+	return s.feedbackRepo.EachLabeledCitation(ctx, workspaceID, since, fn)
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/pkg/config"
+)
+
+// Groundedness checkers selectable in config
+const (
+	GroundingProviderNone = "none"
+	GroundingProviderLLM  = "llm"
+	GroundingProviderNLI  = "nli"
+)
+
+// What happens to answers that fail the groundedness check
+const (
+	GroundingActionFlag   = "flag"
+	GroundingActionReject = "reject"
+)
+
+// rejectedAnswer replaces answers that fail the check in reject mode
+const rejectedAnswer = "The generated answer could not be verified against the provided documents, so it was withheld. Please review the cited sources directly."
+
+var (
+	// claimMarkerPattern is a source marker with the whitespace before it
+	claimMarkerPattern = regexp.MustCompile(`\s*` + sourceMarkerPattern.String())
+	listItemPattern    = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+)
+
+// Claim is a sentence of an answer to be checked against its sources
+type Claim struct {
+	// Text is the sentence without its source markers
+	Text string
+	// Start and End delimit the sentence in the answer, in runes
+	Start int
+	End   int
+	// Sources are the citation numbers (1-based) the claim is checked
+	// against: the ones it cites, or all of them if it cites none
+	Sources []int
+	Cited   bool
+}
+
+// ClaimJudgment is a checker's verdict on one claim
+type ClaimJudgment struct {
+	Supported bool
+	// Score is the confidence that the claim is supported (0-1)
+	Score  float64
+	Reason string
+}
+
+// GroundednessChecker judges whether claims are supported by the sources
+// they are checked against. It returns one judgment per claim, in order.
+type GroundednessChecker interface {
+	Check(ctx context.Context, claims []Claim, sources []*domain.SearchResult) ([]ClaimJudgment, error)
+}
+
+// ClaimVerdict is the outcome of checking one claim, as returned to clients
+type ClaimVerdict struct {
+	Claim     string  `json:"claim"`
+	Start     int     `json:"start"`
+	End       int     `json:"end"`
+	Sources   []int   `json:"sources"`
+	Cited     bool    `json:"cited"`
+	Supported bool    `json:"supported"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason,omitempty"`
+}
+
+// Groundedness is the result of checking an answer against its citations
+type Groundedness struct {
+	Checker string `json:"checker"`
+	// Score is the fraction of claims that are supported (0-1)
+	Score  float64 `json:"score"`
+	Passed bool    `json:"passed"`
+	// Rejected is set when the answer failed the check and was withheld;
+	// Claims still describe the withheld answer
+	Rejected bool           `json:"rejected,omitempty"`
+	Claims   []ClaimVerdict `json:"claims"`
+}
+
+// NewGroundednessChecker builds the checker selected by
+// cfg.Grounding.Provider. It returns nil when checking is disabled.
+func NewGroundednessChecker(cfg *config.Config, llm client.ChatProvider, httpClient *client.HTTPClient) (GroundednessChecker, error) {
+	switch cfg.Grounding.Action {
+	case GroundingActionFlag, GroundingActionReject:
+	default:
+		return nil, fmt.Errorf("unknown grounding action %q", cfg.Grounding.Action)
+	}
+
+	switch cfg.Grounding.Provider {
+	case "", GroundingProviderNone:
+		return nil, nil
+	case GroundingProviderLLM:
+		return &LLMGroundednessChecker{llm: llm}, nil
+	case GroundingProviderNLI:
+		if cfg.Grounding.APIBaseURL == "" {
+			return nil, fmt.Errorf("GROUNDING_API_URL is required for the %s groundedness checker", GroundingProviderNLI)
+		}
+		return &NLIGroundednessChecker{
+			client:    client.NewNLIClient(httpClient, cfg.Grounding.APIBaseURL, cfg.Grounding.APIKey, cfg.Grounding.Model),
+			threshold: cfg.Grounding.EntailmentThreshold,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown grounding provider %q", cfg.Grounding.Provider)
+	}
+}
+
+// checkGroundedness splits the answer into claims, checks them against the
+// cited chunks and attaches the verdicts to resp. In reject mode an answer
+// scoring below the minimum is replaced. If the check itself fails the
+// answer is returned unchecked.
+func (s *ChatService) checkGroundedness(ctx context.Context, resp *QueryResponse) {
+	if s.grounding == nil || len(resp.Citations) == 0 {
+		return
+	}
+
+	claims := answerClaims(resp.Answer, resp.Markers, len(resp.Citations))
+	if len(claims) == 0 {
+		return
+	}
+
+	fmt.Printf("Checking groundedness of %d claims (checker: %s)...\n", len(claims), s.config.Grounding.Provider)
+	start := time.Now()
+	judgments, err := s.grounding.Check(ctx, claims, resp.Citations)
+	if err != nil {
+		fmt.Printf("WARNING: Groundedness check failed, returning unchecked answer: %v\n", err)
+		return
+	}
+
+	result := &Groundedness{
+		Checker: s.config.Grounding.Provider,
+		Claims:  make([]ClaimVerdict, len(claims)),
+	}
+	supported := 0
+	for i, claim := range claims {
+		judgment := judgments[i]
+		if judgment.Supported {
+			supported++
+		}
+		result.Claims[i] = ClaimVerdict{
+			Claim:     claim.Text,
+			Start:     claim.Start,
+			End:       claim.End,
+			Sources:   claim.Sources,
+			Cited:     claim.Cited,
+			Supported: judgment.Supported,
+			Score:     judgment.Score,
+			Reason:    judgment.Reason,
+		}
+	}
+	result.Score = float64(supported) / float64(len(claims))
+	result.Passed = result.Score >= s.config.Grounding.MinScore
+
+	fmt.Printf("Groundedness: %d/%d claims supported (score %.2f, min %.2f) in %v\n",
+		supported, len(claims), result.Score, s.config.Grounding.MinScore, time.Since(start))
+
+	if !result.Passed {
+		for _, verdict := range result.Claims {
+			if !verdict.Supported {
+				fmt.Printf("  Unsupported: %.100s\n", verdict.Claim)
+			}
+		}
+
+		if s.config.Grounding.Action == GroundingActionReject {
+			fmt.Println("WARNING: Answer failed the groundedness check, withholding it")
+			result.Rejected = true
+			resp.Answer = rejectedAnswer
+			resp.Markers = []CitationMarker{}
+		} else {
+			fmt.Println("WARNING: Answer failed the groundedness check, flagging it")
+		}
+	}
+
+	resp.Groundedness = result
+}
+
+// answerClaims splits a normalized answer into sentences. Each sentence is
+// checked against the sources cited by the markers that support it, or
+// against every source if it has none. Sentences without words (a stray
+// marker, a list bullet) are skipped.
+func answerClaims(answer string, markers []CitationMarker, sourceCount int) []Claim {
+	runes := []rune(answer)
+
+	var bounds []int
+	for _, loc := range sentenceEndPattern.FindAllStringIndex(answer, -1) {
+		bounds = append(bounds, utf8.RuneCountInString(answer[:loc[1]]))
+	}
+	bounds = append(bounds, len(runes))
+
+	var claims []Claim
+	start := 0
+	for _, end := range bounds {
+		if end <= start {
+			continue
+		}
+
+		text := claimMarkerPattern.ReplaceAllString(string(runes[start:end]), "")
+		text = listItemPattern.ReplaceAllString(strings.Join(strings.Fields(text), " "), "")
+		if !hasWords(text) {
+			start = end
+			continue
+		}
+
+		claim := Claim{Text: text, Start: start, End: end}
+		for isSpace(runes[claim.Start]) {
+			claim.Start++
+		}
+		for isSpace(runes[claim.End-1]) {
+			claim.End--
+		}
+
+		seen := make(map[int]bool)
+		for _, marker := range markers {
+			// A marker supports the sentence its span overlaps; markers after
+			// the full stop ("fact. [Source 1]") belong to the previous one
+			if marker.SpanStart < end && marker.SpanEnd > start && !seen[marker.Source] {
+				seen[marker.Source] = true
+				claim.Sources = append(claim.Sources, marker.Source)
+			}
+		}
+		if len(claim.Sources) > 0 {
+			claim.Cited = true
+		} else {
+			for n := 1; n <= sourceCount; n++ {
+				claim.Sources = append(claim.Sources, n)
+			}
+		}
+
+		claims = append(claims, claim)
+		start = end
+	}
+
+	return claims
+}
+
+func hasWords(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+// LLMGroundednessChecker asks the chat model to verify each claim against
+// its sources
+type LLMGroundednessChecker struct {
+	llm client.ChatProvider
+}
+
+type llmClaimVerdict struct {
+	Claim     int     `json:"claim"`
+	Supported bool    `json:"supported"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason"`
+}
+
+func (c *LLMGroundednessChecker) Check(ctx context.Context, claims []Claim, sources []*domain.SearchResult) ([]ClaimJudgment, error) {
+	var passages strings.Builder
+	for i, source := range sources {
+		fmt.Fprintf(&passages, "[Source %d]:\n%s\n\n", i+1, truncateRunes(source.Content, 1500))
+	}
+
+	var statements strings.Builder
+	for i, claim := range claims {
+		cited := make([]string, len(claim.Sources))
+		for j, n := range claim.Sources {
+			cited[j] = fmt.Sprintf("Source %d", n)
+		}
+		fmt.Fprintf(&statements, "%d. %s (check against: %s)\n", i+1, claim.Text, strings.Join(cited, ", "))
+	}
+
+	systemPrompt := `You are a fact checker for a document question answering system.
+
+RULES:
+1. For each claim, decide whether it is fully supported by the sources it must be checked against
+2. A claim is supported only if those sources state it or it follows directly from them; general knowledge does not count
+3. A claim that says the sources do not contain the requested information is supported
+4. Give a confidence score from 0 to 1 that the claim is supported, and a short reason for unsupported claims
+5. Return ONLY a JSON array like [{"claim": 1, "supported": true, "score": 0.9, "reason": ""}] covering every claim`
+
+	userPrompt := fmt.Sprintf(`Sources:
+%s
+Claims:
+%s`, passages.String(), statements.String())
+
+	messages := []client.ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+
+	answer, _, err := c.llm.Chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("LLM groundedness checker returned no JSON array: %.200s", answer)
+	}
+
+	var verdicts []llmClaimVerdict
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &verdicts); err != nil {
+		return nil, fmt.Errorf("invalid LLM groundedness checker response: %w", err)
+	}
+
+	// Claims the model skipped count as unsupported
+	judgments := make([]ClaimJudgment, len(claims))
+	for i := range judgments {
+		judgments[i].Reason = "not judged"
+	}
+	for _, verdict := range verdicts {
+		if verdict.Claim < 1 || verdict.Claim > len(claims) {
+			continue
+		}
+		judgments[verdict.Claim-1] = ClaimJudgment{
+			Supported: verdict.Supported,
+			Score:     verdict.Score,
+			Reason:    verdict.Reason,
+		}
+	}
+
+	return judgments, nil
+}
+
+// NLIGroundednessChecker scores each claim against each of its sources with
+// an NLI model; a claim is supported when any source entails it
+type NLIGroundednessChecker struct {
+	client    *client.NLIClient
+	threshold float64
+}
+
+func (c *NLIGroundednessChecker) Check(ctx context.Context, claims []Claim, sources []*domain.SearchResult) ([]ClaimJudgment, error) {
+	var pairs []client.NLIPair
+	var pairClaims, pairSources []int
+	for i, claim := range claims {
+		for _, n := range claim.Sources {
+			pairs = append(pairs, client.NLIPair{
+				Premise:    sources[n-1].Content,
+				Hypothesis: claim.Text,
+			})
+			pairClaims = append(pairClaims, i)
+			pairSources = append(pairSources, n)
+		}
+	}
+
+	results, err := c.client.Infer(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	judgments := make([]ClaimJudgment, len(claims))
+	contradicted := make([]int, len(claims))
+	for _, result := range results {
+		i := pairClaims[result.Index]
+		if result.Entailment > judgments[i].Score {
+			judgments[i].Score = result.Entailment
+		}
+		if result.Contradiction >= c.threshold && contradicted[i] == 0 {
+			contradicted[i] = pairSources[result.Index]
+		}
+	}
+
+	for i := range judgments {
+		judgments[i].Supported = judgments[i].Score >= c.threshold
+		switch {
+		case judgments[i].Supported:
+		case contradicted[i] > 0:
+			judgments[i].Reason = fmt.Sprintf("contradicted by Source %d", contradicted[i])
+		default:
+			judgments[i].Reason = "not entailed by any source"
+		}
+	}
+
+	return judgments, nil
+}
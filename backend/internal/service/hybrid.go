@@ -0,0 +1,76 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+// Search modes selectable per query
+const (
+	SearchModeVector  = "vector"
+	SearchModeKeyword = "keyword"
+	SearchModeHybrid  = "hybrid"
+)
+
+// rrfK dampens the influence of the very top ranks in reciprocal rank fusion
+const rrfK = 60
+
+// ValidSearchMode reports whether mode is a supported search mode
+func ValidSearchMode(mode string) bool {
+	switch mode {
+	case SearchModeVector, SearchModeKeyword, SearchModeHybrid:
+		return true
+	}
+	return false
+}
+
+// fuseResults merges the vector and keyword rankings with weighted reciprocal
+// rank fusion. The fused score is normalized so that a chunk ranked first by
+// both searches scores 1.
+func fuseResults(vectorResults, keywordResults []*domain.SearchResult, vectorWeight float64, limit int) []*domain.SearchResult {
+	keywordWeight := 1 - vectorWeight
+	maxScore := (vectorWeight + keywordWeight) / float64(rrfK+1)
+
+	fused := make(map[string]*domain.SearchResult)
+	var order []string
+
+	add := func(results []*domain.SearchResult, weight float64) {
+		for rank, result := range results {
+			existing, ok := fused[result.ID]
+			if !ok {
+				existing = result
+				existing.Score = 0
+				fused[result.ID] = existing
+				order = append(order, result.ID)
+			} else {
+				if result.VectorScore > 0 {
+					existing.VectorScore = result.VectorScore
+				}
+				if result.KeywordScore > 0 {
+					existing.KeywordScore = result.KeywordScore
+				}
+				existing.IdentifierMatch = existing.IdentifierMatch || result.IdentifierMatch
+			}
+			existing.Score += weight / float64(rrfK+rank+1)
+		}
+	}
+	add(vectorResults, vectorWeight)
+	add(keywordResults, keywordWeight)
+
+	results := make([]*domain.SearchResult, 0, len(order))
+	for _, id := range order {
+		result := fused[id]
+		result.Score /= maxScore
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
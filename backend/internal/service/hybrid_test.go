@@ -0,0 +1,105 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+func vectorResult(id string, score float64) *domain.SearchResult {
+	result := &domain.SearchResult{VectorScore: score}
+	result.ID = id
+	return result
+}
+
+func keywordResult(id string, score float64, identifier bool) *domain.SearchResult {
+	result := &domain.SearchResult{KeywordScore: score, IdentifierMatch: identifier}
+	result.ID = id
+	return result
+}
+
+func resultIDs(results []*domain.SearchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestFuseResultsTopOfBothScoresOne(t *testing.T) {
+	for _, weight := range []float64{0, 0.3, 0.5, 1} {
+		results := fuseResults(
+			[]*domain.SearchResult{vectorResult("a", 0.9), vectorResult("b", 0.5)},
+			[]*domain.SearchResult{keywordResult("a", 0.4, false), keywordResult("c", 0.2, false)},
+			weight, 10)
+
+		if results[0].ID != "a" || math.Abs(results[0].Score-1) > 1e-9 {
+			t.Errorf("weight %v: top result %s scored %v, want a scoring 1", weight, results[0].ID, results[0].Score)
+		}
+	}
+}
+
+func TestFuseResultsWeights(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight float64
+		want   []string
+	}{
+		// Only the keyword ranking counts; vector-only chunks score 0
+		{"keyword only", 0, []string{"k1", "k2", "v1", "v2"}},
+		// Only the vector ranking counts; keyword-only chunks score 0
+		{"vector only", 1, []string{"v1", "v2", "k1", "k2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := fuseResults(
+				[]*domain.SearchResult{vectorResult("v1", 0.9), vectorResult("v2", 0.8)},
+				[]*domain.SearchResult{keywordResult("k1", 0.5, false), keywordResult("k2", 0.4, false)},
+				tt.weight, 10)
+
+			if ids := resultIDs(results); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("order = %v, want %v", ids, tt.want)
+			}
+			for _, result := range results[2:] {
+				if result.Score != 0 {
+					t.Errorf("%s scored %v, want 0", result.ID, result.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestFuseResultsMergesScores(t *testing.T) {
+	results := fuseResults(
+		[]*domain.SearchResult{vectorResult("a", 0.7)},
+		[]*domain.SearchResult{keywordResult("b", 0.6, false), keywordResult("a", 0.3, true)},
+		0.5, 10)
+
+	var merged *domain.SearchResult
+	for _, result := range results {
+		if result.ID == "a" {
+			merged = result
+		}
+	}
+	if len(results) != 2 || merged == nil {
+		t.Fatalf("results = %v, want a and b once each", resultIDs(results))
+	}
+	if merged.VectorScore != 0.7 || merged.KeywordScore != 0.3 || !merged.IdentifierMatch {
+		t.Errorf("merged = vector %v, keyword %v, identifier %v; want 0.7, 0.3, true",
+			merged.VectorScore, merged.KeywordScore, merged.IdentifierMatch)
+	}
+}
+
+func TestFuseResultsLimit(t *testing.T) {
+	results := fuseResults(
+		[]*domain.SearchResult{vectorResult("a", 0.9), vectorResult("b", 0.8), vectorResult("c", 0.7)},
+		[]*domain.SearchResult{keywordResult("d", 0.5, false), keywordResult("a", 0.4, false)},
+		0.5, 2)
+
+	if ids := resultIDs(results); !reflect.DeepEqual(ids, []string{"a", "d"}) {
+		t.Errorf("results = %v, want [a d]", ids)
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"github.com/pdf-rag-system/backend/pkg/config"
+)
+
+// processingError is a document processing failure carrying the error code
+// recorded on the document. Permanent failures are not retried.
+type processingError struct {
+	code      string
+	permanent bool
+	err       error
+}
+
+func (e *processingError) Error() string { return e.err.Error() }
+func (e *processingError) Unwrap() error { return e.err }
+
+// failure wraps a processing error that may go away on retry
+func failure(code string, err error) error {
+	return &processingError{code: code, err: err}
+}
+
+// permanent wraps a processing error that retrying cannot fix
+func permanent(code string, err error) error {
+	return &processingError{code: code, permanent: true, err: err}
+}
+
+func isPermanent(err error) bool {
+	var procErr *processingError
+	return errors.As(err, &procErr) && procErr.permanent
+}
+
+// errorCode returns the document error code for a processing error
+func errorCode(err error) string {
+	var procErr *processingError
+	if errors.As(err, &procErr) {
+		return procErr.code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.ErrCodeTimeout
+	}
+	return domain.ErrCodeInternal
+}
+
+// IngestionWorker runs queued ingestion jobs with a fixed-size pool of
+// workers. Jobs are claimed from Postgres, so several server replicas can
+// share the queue.
+type IngestionWorker struct {
+	jobRepo         *repository.JobRepository
+	documentService *DocumentService
+	config          config.IngestionConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewIngestionWorker(jobRepo *repository.JobRepository, documentService *DocumentService, cfg *config.Config) *IngestionWorker {
+	return &IngestionWorker{
+		jobRepo:         jobRepo,
+		documentService: documentService,
+		config:          cfg.Ingestion,
+	}
+}
+
+// Start recovers jobs interrupted by a previous shutdown and launches the
+// workers. It returns once the workers are running.
+func (w *IngestionWorker) Start(ctx context.Context) error {
+	if err := validateIngestionConfig(w.config); err != nil {
+		return err
+	}
+	if err := w.recover(ctx); err != nil {
+		return err
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	fmt.Printf("Starting %d ingestion workers\n", w.config.Workers)
+	for i := 0; i < w.config.Workers; i++ {
+		w.wg.Add(1)
+		go w.run(ctx, i+1)
+	}
+
+	w.wg.Add(1)
+	go w.watchStale(ctx)
+
+	return nil
+}
+
+// validateIngestionConfig rejects settings the workers cannot run with:
+// without workers uploads would stay processing forever, and zero intervals
+// would busy-poll the database or panic the heartbeat tickers
+func validateIngestionConfig(cfg config.IngestionConfig) error {
+	switch {
+	case cfg.Workers < 1:
+		return fmt.Errorf("INGEST_WORKERS must be at least 1, got %d", cfg.Workers)
+	case cfg.MaxAttempts < 1:
+		return fmt.Errorf("INGEST_MAX_ATTEMPTS must be at least 1, got %d", cfg.MaxAttempts)
+	case cfg.PollInterval <= 0:
+		return fmt.Errorf("INGEST_POLL_INTERVAL must be positive, got %v", cfg.PollInterval)
+	case cfg.JobTimeout <= 0:
+		return fmt.Errorf("INGEST_JOB_TIMEOUT must be positive, got %v", cfg.JobTimeout)
+	case cfg.StaleAfter < 3*time.Millisecond:
+		// The heartbeat ticks every StaleAfter/3
+		return fmt.Errorf("INGEST_STALE_AFTER must be at least 3ms, got %v", cfg.StaleAfter)
+	}
+	return nil
+}
+
+// Stop signals the workers to finish and waits for them. Jobs that are cut
+// short are picked up again by the stale job recovery.
+func (w *IngestionWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// recover requeues jobs left running by a dead worker and creates jobs for
+// documents stuck in processing without one.
+func (w *IngestionWorker) recover(ctx context.Context) error {
+	requeued, err := w.jobRepo.RequeueStale(ctx, time.Now().Add(-w.config.StaleAfter))
+	if err != nil {
+		return fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+	if requeued > 0 {
+		fmt.Printf("Requeued %d interrupted ingestion jobs\n", requeued)
+	}
+
+	orphaned, err := w.jobRepo.ListOrphanedDocumentIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find orphaned documents: %w", err)
+	}
+	for _, docID := range orphaned {
+		if err := w.documentService.enqueue(ctx, docID); err != nil {
+			return fmt.Errorf("failed to queue orphaned document %s: %w", docID, err)
+		}
+	}
+	if len(orphaned) > 0 {
+		fmt.Printf("Queued %d documents stuck in processing\n", len(orphaned))
+	}
+
+	return nil
+}
+
+func (w *IngestionWorker) run(ctx context.Context, workerID int) {
+	defer w.wg.Done()
+
+	for {
+		job, err := w.jobRepo.ClaimNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			fmt.Printf("ERROR: Worker %d failed to claim job: %v\n", workerID, err)
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.config.PollInterval):
+			}
+			continue
+		}
+
+		w.process(ctx, workerID, job)
+	}
+}
+
+func (w *IngestionWorker) process(ctx context.Context, workerID int, job *domain.IngestionJob) {
+	fmt.Printf("Worker %d: processing %s job %s for document %s (attempt %d/%d)\n",
+		workerID, job.Kind, job.ID, job.DocumentID, job.Attempts, job.MaxAttempts)
+
+	jobCtx, cancel := context.WithTimeout(ctx, w.config.JobTimeout)
+	defer cancel()
+
+	// Keep the job's heartbeat fresh so it is not mistaken for a stale one
+	stopHeartbeat := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(w.config.StaleAfter / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+				if err := w.jobRepo.Heartbeat(jobCtx, job.ID); err != nil {
+					fmt.Printf("WARNING: Failed to record heartbeat for job %s: %v\n", job.ID, err)
+				}
+			}
+		}
+	}()
+
+	err := w.documentService.runJob(jobCtx, job)
+	close(stopHeartbeat)
+
+	// Record the outcome even if the server is shutting down
+	finishCtx := context.Background()
+
+	if err == nil {
+		if err := w.jobRepo.MarkSucceeded(finishCtx, job.ID); err != nil {
+			fmt.Printf("ERROR: Failed to mark job %s succeeded: %v\n", job.ID, err)
+		}
+		return
+	}
+
+	if ctx.Err() != nil {
+		// Shutting down: leave the job running so it is requeued on recovery
+		fmt.Printf("Worker %d: job %s interrupted by shutdown\n", workerID, job.ID)
+		return
+	}
+
+	if isPermanent(err) || job.Attempts >= job.MaxAttempts {
+		fmt.Printf("ERROR: Job %s for document %s failed: %v\n", job.ID, job.DocumentID, err)
+		if err := w.jobRepo.MarkFailed(finishCtx, job.ID, err.Error()); err != nil {
+			fmt.Printf("ERROR: Failed to mark job %s failed: %v\n", job.ID, err)
+		}
+		w.documentService.failJob(finishCtx, job, err)
+		return
+	}
+
+	runAt := time.Now().Add(w.backoff(job.Attempts))
+	fmt.Printf("WARNING: Job %s attempt %d failed, retrying at %s: %v\n",
+		job.ID, job.Attempts, runAt.Format(time.RFC3339), err)
+	if err := w.jobRepo.Reschedule(finishCtx, job.ID, err.Error(), runAt); err != nil {
+		fmt.Printf("ERROR: Failed to reschedule job %s: %v\n", job.ID, err)
+	}
+	w.documentService.recordRetry(finishCtx, job, err)
+}
+
+// backoff doubles the retry delay with each attempt, up to RetryBackoffMax
+func (w *IngestionWorker) backoff(attempt int) time.Duration {
+	delay := w.config.RetryBackoff
+	for i := 1; i < attempt && delay < w.config.RetryBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > w.config.RetryBackoffMax {
+		delay = w.config.RetryBackoffMax
+	}
+	return delay
+}
+
+// watchStale periodically requeues jobs whose worker died, including
+// workers on other replicas.
+func (w *IngestionWorker) watchStale(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.StaleAfter)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requeued, err := w.jobRepo.RequeueStale(ctx, time.Now().Add(-w.config.StaleAfter))
+			if err != nil && ctx.Err() == nil {
+				fmt.Printf("ERROR: Failed to requeue stale jobs: %v\n", err)
+			} else if requeued > 0 {
+				fmt.Printf("Requeued %d stale ingestion jobs\n", requeued)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// pdfInfoTimeout bounds reading a PDF's info dictionary at upload
+const pdfInfoTimeout = 10 * time.Second
+
+// pdfInfo is the part of a PDF's info dictionary kept on the document
+type pdfInfo struct {
+	Title        string
+	Author       string
+	CreationDate *time.Time
+}
+
+// readPDFInfo reads the info dictionary with poppler's pdfinfo, which the
+// backend image installs for page rendering
+func readPDFInfo(ctx context.Context, path string) (*pdfInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, pdfInfoTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "pdfinfo", "-isodates", "-enc", "UTF-8", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("pdfinfo failed: %w", err)
+	}
+	return parsePDFInfo(string(output)), nil
+}
+
+// parsePDFInfo reads the "Key: value" lines printed by pdfinfo
+func parsePDFInfo(output string) *pdfInfo {
+	info := &pdfInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Title":
+			info.Title = truncateRunes(value, 512)
+		case "Author":
+			info.Author = truncateRunes(value, 255)
+		case "CreationDate":
+			if date, ok := parsePDFDate(value); ok {
+				info.CreationDate = &date
+			}
+		}
+	}
+	return info
+}
+
+// pdfDateLayouts are the forms of pdfinfo -isodates output, which omits the
+// zone minutes when they are zero and the zone when the PDF has none
+var pdfDateLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05Z07",
+	"2006-01-02T15:04:05",
+}
+
+func parsePDFDate(value string) (time.Time, bool) {
+	for _, layout := range pdfDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
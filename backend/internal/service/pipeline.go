@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+// embedBatch is a run of consecutive chunks embedded in a single request
+type embedBatch struct {
+	start int
+	texts []string
+}
+
+// embedChunks embeds the chunk texts in space in batches of
+// EmbeddingConfig.BatchSize with up to EmbeddingConfig.Concurrency requests
+// in flight. It returns one embedding per text and, for texts whose batch
+// still failed after retries, a nil embedding and the batch's error in errs.
+// An error is returned only if ctx is cancelled. Progress is recorded on the
+// document when reportProgress is set.
+func (s *DocumentService) embedChunks(ctx context.Context, docID string, space *domain.EmbeddingSpace, chunkTexts []string, reportProgress bool) (embeddings [][]float64, errs []error, err error) {
+	batchSize := s.config.Embedding.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	concurrency := s.config.Embedding.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var batches []embedBatch
+	for start := 0; start < len(chunkTexts); start += batchSize {
+		end := start + batchSize
+		if end > len(chunkTexts) {
+			end = len(chunkTexts)
+		}
+		batches = append(batches, embedBatch{start: start, texts: chunkTexts[start:end]})
+	}
+
+	fmt.Printf("Embedding %d chunks in %d batches (batch size %d, concurrency %d)\n",
+		len(chunkTexts), len(batches), batchSize, concurrency)
+
+	embeddings = make([][]float64, len(chunkTexts))
+	errs = make([]error, len(chunkTexts))
+	var done int64
+	total := int64(len(chunkTexts))
+	var progress *progressReporter
+	if reportProgress {
+		progress = &progressReporter{
+			service: s,
+			docID:   docID,
+			total:   len(chunkTexts),
+			started: time.Now(),
+		}
+	}
+
+	batchCh := make(chan embedBatch)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchCh {
+				// Each batch owns a distinct range of the slices
+				vectors, err := s.embeddings.Embed(ctx, space, batch.texts)
+				if err != nil {
+					if ctx.Err() == nil {
+						fmt.Printf("WARNING: Failed to embed chunks %d-%d in document %s: %v\n",
+							batch.start, batch.start+len(batch.texts)-1, docID, err)
+					}
+					for i := range batch.texts {
+						errs[batch.start+i] = err
+					}
+				} else {
+					copy(embeddings[batch.start:], vectors)
+				}
+
+				completed := atomic.AddInt64(&done, int64(len(batch.texts)))
+				fmt.Printf("Progress: %d/%d chunks (%.1f%%)\n", completed, total, float64(completed)*100/float64(total))
+				progress.report(ctx, int(completed))
+			}
+		}()
+	}
+
+	for _, batch := range batches {
+		select {
+		case batchCh <- batch:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(batchCh)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return embeddings, errs, nil
+}
+
+// progressWriteInterval limits how often chunk progress is written to the DB
+const progressWriteInterval = time.Second
+
+// progressReporter records embedding progress and an ETA on the document
+type progressReporter struct {
+	service *DocumentService
+	docID   string
+	total   int
+	started time.Time
+
+	mu        sync.Mutex
+	lastWrite time.Time
+	lastDone  int
+}
+
+// report records progress; a nil reporter does nothing
+func (p *progressReporter) report(ctx context.Context, done int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if done <= p.lastDone {
+		return
+	}
+	if done < p.total && time.Since(p.lastWrite) < progressWriteInterval {
+		return
+	}
+
+	// Extrapolate the rate so far to the remaining chunks
+	elapsed := time.Since(p.started)
+	eta := p.started.Add(time.Duration(float64(elapsed) * float64(p.total) / float64(done)))
+
+	p.service.setProgress(ctx, p.docID, map[string]interface{}{
+		"chunks_done": done,
+		"eta":         eta,
+	})
+	p.lastWrite = time.Now()
+	p.lastDone = done
+}
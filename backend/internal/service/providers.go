@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/pkg/config"
+)
+
+// NewHTTPClient builds the HTTP client shared by every API client, so they
+// pool connections and trip the same circuit breakers
+func NewHTTPClient(cfg *config.Config) *client.HTTPClient {
+	return client.NewHTTPClient(client.HTTPOptions{
+		Timeout:             cfg.HTTP.Timeout,
+		StreamTimeout:       cfg.HTTP.StreamTimeout,
+		MaxRetries:          cfg.HTTP.MaxRetries,
+		RetryBackoff:        cfg.HTTP.RetryBackoff,
+		RetryBackoffMax:     cfg.HTTP.RetryBackoffMax,
+		BreakerFailures:     cfg.HTTP.BreakerFailures,
+		BreakerCooldown:     cfg.HTTP.BreakerCooldown,
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+	})
+}
+
+// NewChatProvider builds the chat API client selected by cfg.LLM.Provider.
+// It is shared by answer generation, the LLM reranker and the LLM
+// groundedness checker.
+func NewChatProvider(cfg *config.Config, httpClient *client.HTTPClient) (client.ChatProvider, error) {
+	return client.NewChatProvider(client.ProviderConfig{
+		Provider:   cfg.LLM.Provider,
+		BaseURL:    cfg.LLM.APIBaseURL,
+		APIKey:     cfg.LLM.APIKey,
+		Model:      cfg.LLM.Model,
+		APIVersion: cfg.LLM.APIVersion,
+		Options: client.ChatOptions{
+			Temperature: cfg.LLM.Temperature,
+			TopP:        cfg.LLM.TopP,
+			MaxTokens:   cfg.LLM.MaxTokens,
+			Stop:        cfg.LLM.Stop,
+		},
+		HTTP: httpClient,
+	})
+}
+
+// NewEmbedder builds the embeddings API client selected by
+// cfg.Embedding.Provider. The model is chosen per request by the embedding
+// space. Embedding requests retry with the embedding retry settings.
+func NewEmbedder(cfg *config.Config, httpClient *client.HTTPClient) (client.Embedder, error) {
+	return client.NewEmbedder(client.ProviderConfig{
+		Provider:   cfg.Embedding.Provider,
+		BaseURL:    cfg.Embedding.APIBaseURL,
+		APIKey:     cfg.Embedding.APIKey,
+		APIVersion: cfg.Embedding.APIVersion,
+		HTTP:       httpClient.WithRetry(cfg.Embedding.MaxRetries, cfg.Embedding.RetryBackoff),
+	})
+}
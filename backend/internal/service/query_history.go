@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/internal/domain"
+)
+
+// queryTrace collects the timings and token usage of one query so it can be
+// recorded in query_history
+type queryTrace struct {
+	start      time.Time
+	question   string
+	searchMode string
+	embed      time.Duration
+	search     time.Duration
+	llm        time.Duration
+	usage      client.TokenUsage
+}
+
+func newQueryTrace() *queryTrace {
+	return &queryTrace{start: time.Now()}
+}
+
+// recordQuery stores the outcome of a query. Failing to record is logged but
+// does not affect the query. Without a history repository (offline
+// evaluation) nothing is recorded.
+func (s *ChatService) recordQuery(req *QueryRequest, trace *queryTrace, resp *QueryResponse, queryErr error) {
+	if s.historyRepo == nil {
+		return
+	}
+
+	entry := &domain.QueryHistory{
+		WorkspaceID:      req.WorkspaceID,
+		Query:            req.Query,
+		DocumentIDs:      domain.StringList(req.DocumentIDs),
+		SearchMode:       trace.searchMode,
+		ResponseTimeMs:   time.Since(trace.start).Milliseconds(),
+		EmbedMs:          trace.embed.Milliseconds(),
+		SearchMs:         trace.search.Milliseconds(),
+		LLMMs:            trace.llm.Milliseconds(),
+		PromptTokens:     trace.usage.PromptTokens,
+		CompletionTokens: trace.usage.CompletionTokens,
+		TotalTokens:      trace.usage.TotalTokens,
+	}
+	if trace.question != "" && trace.question != req.Query {
+		entry.RewrittenQuery = trace.question
+	}
+
+	if queryErr != nil {
+		entry.Error = queryErr.Error()
+	}
+	if resp != nil {
+		entry.SessionID = resp.SessionID
+		entry.Response = resp.Answer
+		entry.Citations = domain.NewTurnCitations(resp.Citations)
+		entry.Scores = domain.NewRetrievalScores(resp.Citations)
+		entry.ResultCount = len(resp.Citations)
+		entry.Uncited = resp.Uncited
+	}
+
+	// The request context may already be cancelled (e.g. a disconnected stream)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.historyRepo.Create(ctx, entry); err != nil {
+		fmt.Printf("WARNING: Failed to record query history: %v\n", err)
+		return
+	}
+	if resp != nil {
+		resp.QueryID = entry.ID
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pdf-rag-system/backend/pkg/config"
+)
+
+// Rate limit budgets; each key has a separate bucket per budget
+const (
+	BudgetQueries = "queries"
+	BudgetUploads = "uploads"
+)
+
+// idleBucketTTL is how long an untouched bucket is kept; by then it has
+// usually refilled, so dropping it changes little
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimit is the outcome of taking a token from a bucket
+type RateLimit struct {
+	Allowed bool
+	// Limit is the bucket size and Remaining the tokens left in it
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next token when not allowed
+	RetryAfter time.Duration
+}
+
+// RateLimiter keeps an in-memory token bucket per client and budget. Buckets
+// refill continuously at the budget's rate per minute up to its burst.
+type RateLimiter struct {
+	budgets map[string]config.RateBudget
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func NewRateLimiter(cfg *config.Config) *RateLimiter {
+	return &RateLimiter{
+		budgets: map[string]config.RateBudget{
+			BudgetQueries: cfg.RateLimit.Queries,
+			BudgetUploads: cfg.RateLimit.Uploads,
+		},
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take takes a token for client from the named budget. perMinute overrides
+// the budget's configured rate when not nil; a rate of zero is unlimited.
+func (l *RateLimiter) Take(client, budget string, perMinute *int) RateLimit {
+	limit := l.budgets[budget]
+	if perMinute != nil {
+		limit.PerMinute = *perMinute
+	}
+	if limit.PerMinute <= 0 {
+		return RateLimit{Allowed: true}
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.PerMinute
+	}
+
+	rate := float64(limit.PerMinute) / float64(time.Minute)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	key := budget + ":" + client
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), updated: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+float64(now.Sub(bucket.updated))*rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return RateLimit{
+			Limit:      burst,
+			RetryAfter: time.Duration((1 - bucket.tokens) / rate),
+		}
+	}
+
+	bucket.tokens--
+	return RateLimit{
+		Allowed:   true,
+		Limit:     burst,
+		Remaining: int(bucket.tokens),
+	}
+}
+
+// sweep drops idle buckets now and then so the map does not grow with
+// every client ever seen
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pdf-rag-system/backend/internal/client"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/pkg/config"
+)
+
+// Reranker providers selectable in config
+const (
+	RerankProviderNone         = "none"
+	RerankProviderCrossEncoder = "cross-encoder"
+	RerankProviderLLM          = "llm"
+)
+
+// Reranker reorders retrieved chunks by relevance to the query. It sets
+// RerankScore on each result and returns them best first.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, results []*domain.SearchResult) ([]*domain.SearchResult, error)
+}
+
+// NewReranker builds the reranker selected by cfg.Rerank.Provider
+func NewReranker(cfg *config.Config, llm client.ChatProvider, httpClient *client.HTTPClient) (Reranker, error) {
+	switch cfg.Rerank.Provider {
+	case "", RerankProviderNone:
+		return NoopReranker{}, nil
+	case RerankProviderCrossEncoder:
+		if cfg.Rerank.APIBaseURL == "" {
+			return nil, fmt.Errorf("RERANK_API_URL is required for the %s reranker", RerankProviderCrossEncoder)
+		}
+		return &CrossEncoderReranker{
+			client: client.NewRerankClient(httpClient, cfg.Rerank.APIBaseURL, cfg.Rerank.APIKey, cfg.Rerank.Model),
+		}, nil
+	case RerankProviderLLM:
+		return &LLMReranker{llm: llm}, nil
+	default:
+		return nil, fmt.Errorf("unknown rerank provider %q", cfg.Rerank.Provider)
+	}
+}
+
+// NoopReranker keeps the retrieval order
+type NoopReranker struct{}
+
+func (NoopReranker) Rerank(ctx context.Context, query string, results []*domain.SearchResult) ([]*domain.SearchResult, error) {
+	for _, result := range results {
+		result.RerankScore = result.Score
+	}
+	return results, nil
+}
+
+// CrossEncoderReranker scores chunks with a /rerank endpoint
+type CrossEncoderReranker struct {
+	client *client.RerankClient
+}
+
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, results []*domain.SearchResult) ([]*domain.SearchResult, error) {
+	documents := make([]string, len(results))
+	for i, result := range results {
+		documents[i] = result.Content
+	}
+
+	scores, err := r.client.Rerank(ctx, query, documents, len(documents))
+	if err != nil {
+		return nil, err
+	}
+
+	reranked := make([]*domain.SearchResult, 0, len(scores))
+	for _, score := range scores {
+		result := results[score.Index]
+		result.RerankScore = score.RelevanceScore
+		reranked = append(reranked, result)
+	}
+	sortByRerankScore(reranked)
+
+	return reranked, nil
+}
+
+// LLMReranker asks the chat model to grade each chunk's relevance
+type LLMReranker struct {
+	llm client.ChatProvider
+}
+
+type llmRelevance struct {
+	Source int     `json:"source"`
+	Score  float64 `json:"score"`
+}
+
+func (r *LLMReranker) Rerank(ctx context.Context, query string, results []*domain.SearchResult) ([]*domain.SearchResult, error) {
+	var passages strings.Builder
+	for i, result := range results {
+		fmt.Fprintf(&passages, "[Source %d]:\n%s\n\n", i+1, truncateRunes(result.Content, 1000))
+	}
+
+	systemPrompt := `You are a relevance judge for a document search engine.
+
+RULES:
+1. Grade how well each source helps answer the question, from 0 (irrelevant) to 10 (directly answers it)
+2. Judge each source independently and only on its content
+3. Return ONLY a JSON array like [{"source": 1, "score": 7}, {"source": 2, "score": 0}] covering every source`
+
+	userPrompt := fmt.Sprintf(`Question: %s
+
+Sources:
+%s`, query, passages.String())
+
+	messages := []client.ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+
+	answer, _, err := r.llm.Chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("LLM reranker returned no JSON array: %.200s", answer)
+	}
+
+	var grades []llmRelevance
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &grades); err != nil {
+		return nil, fmt.Errorf("invalid LLM reranker response: %w", err)
+	}
+
+	// Sources the model skipped keep a score of 0
+	for _, result := range results {
+		result.RerankScore = 0
+	}
+	for _, grade := range grades {
+		if grade.Source >= 1 && grade.Source <= len(results) {
+			results[grade.Source-1].RerankScore = grade.Score / 10
+		}
+	}
+
+	reranked := append([]*domain.SearchResult(nil), results...)
+	sortByRerankScore(reranked)
+
+	return reranked, nil
+}
+
+func sortByRerankScore(results []*domain.SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].RerankScore > results[j].RerankScore
+	})
+}
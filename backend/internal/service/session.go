@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrSessionNotFound is returned when a session ID does not exist
+var ErrSessionNotFound = errors.New("session not found")
+
+// maxHistoryTurns is how many previous turns are used to rewrite a follow-up
+const maxHistoryTurns = 6
+
+type SessionService struct {
+	sessionRepo *repository.SessionRepository
+}
+
+func NewSessionService(sessionRepo *repository.SessionRepository) *SessionService {
+	return &SessionService{sessionRepo: sessionRepo}
+}
+
+func (s *SessionService) List(ctx context.Context, workspaceID string) ([]*domain.Session, error) {
+	return s.sessionRepo.List(ctx, workspaceID)
+}
+
+func (s *SessionService) Get(ctx context.Context, workspaceID, id string) (*domain.Session, error) {
+	session, err := s.sessionRepo.GetByID(ctx, workspaceID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return session, err
+}
+
+func (s *SessionService) Delete(ctx context.Context, workspaceID, id string) error {
+	if _, err := s.Get(ctx, workspaceID, id); err != nil {
+		return err
+	}
+	return s.sessionRepo.Delete(ctx, id)
+}
+
+// Resolve returns the workspace's session for id together with its most
+// recent turns. An empty id starts a new session titled after the first
+// question; it is not stored until AppendTurn records that question, so a
+// first question that fails leaves no empty session behind.
+func (s *SessionService) Resolve(ctx context.Context, workspaceID, id, query string) (*domain.Session, []*domain.SessionTurn, error) {
+	if id == "" {
+		session := &domain.Session{
+			ID:          uuid.New().String(),
+			WorkspaceID: workspaceID,
+			Title:       truncateRunes(query, 100),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		return session, nil, nil
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, workspaceID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	turns, err := s.sessionRepo.RecentTurns(ctx, id, maxHistoryTurns)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load session history: %w", err)
+	}
+
+	return session, turns, nil
+}
+
+// AppendTurn records a completed question and answer in the session,
+// storing the session itself if this is its first turn
+func (s *SessionService) AppendTurn(ctx context.Context, session *domain.Session, req *QueryRequest, rewrittenQuery string, resp *QueryResponse) error {
+	turn := &domain.SessionTurn{
+		ID:             uuid.New().String(),
+		SessionID:      session.ID,
+		Query:          req.Query,
+		RewrittenQuery: rewrittenQuery,
+		Answer:         resp.Answer,
+		DocumentIDs:    domain.StringList(req.DocumentIDs),
+		Citations:      domain.NewTurnCitations(resp.Citations),
+		CreatedAt:      time.Now(),
+	}
+	return s.sessionRepo.AppendTurn(ctx, session, turn)
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pdf-rag-system/backend/internal/domain"
+	"github.com/pdf-rag-system/backend/internal/repository"
+	"github.com/pdf-rag-system/backend/pkg/config"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWorkspaceNotFound is returned when a workspace ID does not exist
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrInvalidWorkspace is returned for a workspace that cannot be saved
+	ErrInvalidWorkspace = errors.New("invalid workspace")
+	// ErrWorkspaceNotEmpty is returned when deleting a workspace that still
+	// holds documents
+	ErrWorkspaceNotEmpty = errors.New("workspace still has documents")
+	// ErrQuotaExceeded is returned when an upload would exceed a quota of
+	// its workspace
+	ErrQuotaExceeded = errors.New("workspace quota exceeded")
+)
+
+// WorkspaceService manages workspaces and enforces their quotas
+type WorkspaceService struct {
+	repo   *repository.WorkspaceRepository
+	config *config.Config
+}
+
+func NewWorkspaceService(repo *repository.WorkspaceRepository, cfg *config.Config) *WorkspaceService {
+	return &WorkspaceService{
+		repo:   repo,
+		config: cfg,
+	}
+}
+
+// WorkspaceRequest creates a workspace or replaces its settings. Unset
+// quotas fall back to the configured defaults; 0 means unlimited.
+type WorkspaceRequest struct {
+	Name            string `json:"name"`
+	MaxDocuments    *int   `json:"max_documents"`
+	MaxStorageBytes *int64 `json:"max_storage_bytes"`
+}
+
+// WorkspaceQuota is the quota in force for a workspace (0 means unlimited)
+type WorkspaceQuota struct {
+	MaxDocuments    int   `json:"max_documents"`
+	MaxStorageBytes int64 `json:"max_storage_bytes"`
+}
+
+// WorkspaceDetails is a workspace with its quota in force and current usage
+type WorkspaceDetails struct {
+	*domain.Workspace
+	Quota WorkspaceQuota         `json:"quota"`
+	Usage *domain.WorkspaceUsage `json:"usage"`
+}
+
+func (s *WorkspaceService) Create(ctx context.Context, req WorkspaceRequest) (*domain.Workspace, error) {
+	workspace := &domain.Workspace{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := applyWorkspaceRequest(workspace, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+func (s *WorkspaceService) List(ctx context.Context) ([]*domain.Workspace, error) {
+	return s.repo.List(ctx)
+}
+
+// Get returns the workspace with its quota and usage
+func (s *WorkspaceService) Get(ctx context.Context, id string) (*WorkspaceDetails, error) {
+	workspace, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.Usage(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace usage: %w", err)
+	}
+
+	return &WorkspaceDetails{
+		Workspace: workspace,
+		Quota:     s.quota(workspace),
+		Usage:     usage,
+	}, nil
+}
+
+// Exists reports whether the workspace exists
+func (s *WorkspaceService) Exists(ctx context.Context, id string) (bool, error) {
+	_, err := s.get(ctx, id)
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Update replaces the name and quotas of a workspace. Lowering a quota below
+// the current usage only blocks further uploads.
+func (s *WorkspaceService) Update(ctx context.Context, id string, req WorkspaceRequest) (*domain.Workspace, error) {
+	workspace, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyWorkspaceRequest(workspace, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+	return s.get(ctx, id)
+}
+
+// Delete removes an empty workspace along with its sessions, recorded
+// queries, collections and API keys. The default workspace cannot be deleted.
+func (s *WorkspaceService) Delete(ctx context.Context, id string) error {
+	if id == domain.DefaultWorkspaceID {
+		return fmt.Errorf("%w: the default workspace cannot be deleted", ErrInvalidWorkspace)
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+
+	usage, err := s.repo.Usage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace usage: %w", err)
+	}
+	if usage.Documents > 0 {
+		return fmt.Errorf("%w: delete its %d documents first", ErrWorkspaceNotEmpty, usage.Documents)
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// CreateDocument stores a new document if its workspace has room for it.
+// Aliases take up a document but no storage, since they share a file.
+func (s *WorkspaceService) CreateDocument(ctx context.Context, doc *domain.Document) error {
+	size := doc.FileSize
+	if doc.AliasOf != nil {
+		size = 0
+	}
+
+	err := s.repo.CreateDocument(ctx, doc, func(workspace *domain.Workspace, usage *domain.WorkspaceUsage) error {
+		quota := s.quota(workspace)
+		if quota.MaxDocuments > 0 && usage.Documents+1 > int64(quota.MaxDocuments) {
+			return fmt.Errorf("%w: workspace holds %d of %d documents", ErrQuotaExceeded, usage.Documents, quota.MaxDocuments)
+		}
+		if quota.MaxStorageBytes > 0 && usage.StorageBytes+size > quota.MaxStorageBytes {
+			return fmt.Errorf("%w: %d bytes would exceed the storage quota of %d bytes (%d used)",
+				ErrQuotaExceeded, size, quota.MaxStorageBytes, usage.StorageBytes)
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWorkspaceNotFound
+	}
+	return err
+}
+
+func (s *WorkspaceService) get(ctx context.Context, id string) (*domain.Workspace, error) {
+	workspace, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// quota resolves the workspace's overrides against the configured defaults
+func (s *WorkspaceService) quota(workspace *domain.Workspace) WorkspaceQuota {
+	quota := WorkspaceQuota{
+		MaxDocuments:    s.config.Workspace.MaxDocuments,
+		MaxStorageBytes: s.config.Workspace.MaxStorageBytes,
+	}
+	if workspace.MaxDocuments != nil {
+		quota.MaxDocuments = *workspace.MaxDocuments
+	}
+	if workspace.MaxStorageBytes != nil {
+		quota.MaxStorageBytes = *workspace.MaxStorageBytes
+	}
+	return quota
+}
+
+func applyWorkspaceRequest(workspace *domain.Workspace, req WorkspaceRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidWorkspace)
+	}
+	if req.MaxDocuments != nil && *req.MaxDocuments < 0 {
+		return fmt.Errorf("%w: max_documents must not be negative", ErrInvalidWorkspace)
+	}
+	if req.MaxStorageBytes != nil && *req.MaxStorageBytes < 0 {
+		return fmt.Errorf("%w: max_storage_bytes must not be negative", ErrInvalidWorkspace)
+	}
+
+	workspace.Name = name
+	workspace.MaxDocuments = req.MaxDocuments
+	workspace.MaxStorageBytes = req.MaxStorageBytes
+	return nil
+}
@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	Database  DatabaseConfig
@@ -9,6 +14,15 @@ type Config struct {
 	LLM       LLMConfig
 	Embedding EmbeddingConfig
 	Upload    UploadConfig
+	Search    SearchConfig
+	Rerank    RerankConfig
+	Grounding GroundingConfig
+	HTTP      HTTPConfig
+	Auth      AuthConfig
+	RateLimit RateLimitConfig
+	Workspace WorkspaceConfig
+	Ingestion IngestionConfig
+	Chunking  ChunkingConfig
 }
 
 type DatabaseConfig struct {
@@ -17,6 +31,8 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	DBName   string
+	// AutoMigrate applies pending migrations when the server starts
+	AutoMigrate bool
 }
 
 type DocReaderConfig struct {
@@ -30,16 +46,46 @@ type ServerConfig struct {
 }
 
 type LLMConfig struct {
+	// Provider selects the chat API: openai, azure, anthropic or ollama
+	Provider string
+	// APIBaseURL is the API root; empty uses the provider's default. For
+	// azure it is the resource endpoint and Model is the deployment name.
 	APIBaseURL string
 	APIKey     string
 	Model      string
+	// APIVersion is the api-version of azure requests
+	APIVersion string
+	// Generation settings; nil or zero leaves the provider's default
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+	Stop        []string
 }
 
 type EmbeddingConfig struct {
+	// Provider selects the embeddings API: openai, azure or ollama
+	Provider   string
+	APIVersion string
 	APIBaseURL string
 	APIKey     string
 	Model      string
-	Dimension  int
+	// ModelVersion optionally distinguishes releases of the same model name
+	ModelVersion string
+	// Dimension is the size of the embeddings Model produces; it is checked
+	// against the endpoint at startup
+	Dimension int
+	// BatchSize is how many chunks are sent per /embeddings request
+	BatchSize int
+	// Concurrency is how many embedding requests run at once per document
+	Concurrency int
+	// MaxRetries is how often a failed embedding request is retried on 429 or
+	// transient errors; the backoff is jittered and capped like other calls
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// CacheSize is how many embeddings the in-process cache holds (0 disables it)
+	CacheSize int
+	// CachePersist stores embeddings in the embedding_cache table
+	CachePersist bool
 }
 
 type UploadConfig struct {
@@ -47,6 +93,114 @@ type UploadConfig struct {
 	MaxFileSize int64
 }
 
+// ChunkingConfig is the default chunking of new documents; reindexing can
+// override it per document
+type ChunkingConfig struct {
+	Size    int
+	Overlap int
+}
+
+type IngestionConfig struct {
+	// Workers is how many documents are processed concurrently
+	Workers     int
+	MaxAttempts int
+	// PollInterval is how often idle workers check for new jobs
+	PollInterval time.Duration
+	// RetryBackoff is the delay before the first retry; it doubles per attempt
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+	JobTimeout      time.Duration
+	// StaleAfter is how long a running job may go without a heartbeat
+	// before it is considered abandoned and requeued
+	StaleAfter time.Duration
+}
+
+type SearchConfig struct {
+	// Mode is the default retrieval mode: vector, keyword or hybrid
+	Mode string
+	// VectorWeight is the default weight of vector vs keyword ranks (0-1)
+	VectorWeight float64
+	// SimilarityThreshold drops vector-only matches at or below this score
+	SimilarityThreshold float64
+}
+
+type RerankConfig struct {
+	// Provider selects the reranker: none, cross-encoder or llm
+	Provider   string
+	APIBaseURL string
+	APIKey     string
+	Model      string
+	// CandidateCount is how many chunks are retrieved before reranking
+	CandidateCount int
+	// TopN is how many reranked chunks are passed to the LLM
+	TopN int
+	// MinScore drops reranked chunks scoring below it
+	MinScore float64
+}
+
+// GroundingConfig controls the check of generated answers against the
+// chunks they cite
+type GroundingConfig struct {
+	// Provider selects the checker: none, llm or nli
+	Provider   string
+	APIBaseURL string
+	APIKey     string
+	Model      string
+	// Action is what happens to answers scoring below MinScore: flag or reject
+	Action string
+	// MinScore is the fraction of claims that must be supported (0-1)
+	MinScore float64
+	// EntailmentThreshold is the entailment probability at which the nli
+	// checker counts a claim as supported
+	EntailmentThreshold float64
+}
+
+// HTTPConfig tunes the HTTP client shared by the LLM, embedding, rerank and
+// NLI API calls
+type HTTPConfig struct {
+	// Timeout bounds one attempt of a regular call
+	Timeout time.Duration
+	// StreamTimeout bounds one attempt of a streamed answer
+	StreamTimeout time.Duration
+	// MaxRetries and RetryBackoff apply to chat, rerank and NLI calls;
+	// embeddings use EmbeddingConfig.MaxRetries and RetryBackoff
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+	// BreakerFailures consecutive failures open an endpoint's circuit for
+	// BreakerCooldown (0 disables the breaker)
+	BreakerFailures     int
+	BreakerCooldown     time.Duration
+	MaxIdleConnsPerHost int
+}
+
+// AuthConfig controls API key authentication
+type AuthConfig struct {
+	// Enabled requires an API key on every /api/v1 route
+	Enabled bool
+}
+
+// RateLimitConfig holds the default per-key token bucket budgets. Keys may
+// override the rates; without authentication clients are told apart by IP.
+type RateLimitConfig struct {
+	Queries RateBudget
+	Uploads RateBudget
+}
+
+// RateBudget is a token bucket refilling PerMinute tokens per minute up to
+// Burst (0 disables the limit)
+type RateBudget struct {
+	PerMinute int
+	Burst     int
+}
+
+// WorkspaceConfig holds the default quotas of a workspace, which each
+// workspace may override (0 means unlimited)
+type WorkspaceConfig struct {
+	MaxDocuments    int
+	MaxStorageBytes int64
+}
+
 func Load() *Config {
 	return &Config{
 		Database: DatabaseConfig{
@@ -55,6 +209,8 @@ func Load() *Config {
 			User:     getEnv("DB_USER", "postgres"),
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "pdf_rag_db"),
+
+			AutoMigrate: getEnvBool("DB_AUTO_MIGRATE", true),
 		},
 		DocReader: DocReaderConfig{
 			Host: getEnv("DOCREADER_HOST", "localhost"),
@@ -65,20 +221,98 @@ func Load() *Config {
 			Host: getEnv("SERVER_HOST", "0.0.0.0"),
 		},
 		LLM: LLMConfig{
-			APIBaseURL: getEnv("LLM_API_BASE_URL", "https://api.openai.com/v1"),
-			APIKey:     getEnv("LLM_API_KEY", ""),
-			Model:      getEnv("LLM_MODEL", "gpt-4"),
+			Provider:    getEnv("LLM_PROVIDER", "openai"),
+			APIBaseURL:  getEnv("LLM_API_BASE_URL", ""),
+			APIKey:      getEnv("LLM_API_KEY", ""),
+			Model:       getEnv("LLM_MODEL", "gpt-4"),
+			APIVersion:  getEnv("LLM_API_VERSION", "2024-06-01"),
+			Temperature: getEnvOptionalFloat("LLM_TEMPERATURE"),
+			TopP:        getEnvOptionalFloat("LLM_TOP_P"),
+			MaxTokens:   getEnvInt("LLM_MAX_TOKENS", 0),
+			Stop:        getEnvList("LLM_STOP"),
 		},
 		Embedding: EmbeddingConfig{
-			APIBaseURL: getEnv("EMBEDDING_API_URL", "https://api.openai.com/v1"),
-			APIKey:     getEnv("EMBEDDING_API_KEY", ""),
-			Model:      getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
-			Dimension:  1536,
+			Provider:     getEnv("EMBEDDING_PROVIDER", "openai"),
+			APIVersion:   getEnv("EMBEDDING_API_VERSION", "2024-06-01"),
+			APIBaseURL:   getEnv("EMBEDDING_API_URL", ""),
+			APIKey:       getEnv("EMBEDDING_API_KEY", ""),
+			Model:        getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+			ModelVersion: getEnv("EMBEDDING_MODEL_VERSION", ""),
+			Dimension:    getEnvInt("EMBEDDING_DIMENSION", getEnvInt("VECTOR_DIMENSION", 1536)),
+			BatchSize:    getEnvInt("EMBEDDING_BATCH_SIZE", 64),
+			Concurrency:  getEnvInt("EMBEDDING_CONCURRENCY", 4),
+			MaxRetries:   getEnvInt("EMBEDDING_MAX_RETRIES", 3),
+			RetryBackoff: getEnvDuration("EMBEDDING_RETRY_BACKOFF", time.Second),
+			CacheSize:    getEnvInt("EMBEDDING_CACHE_SIZE", 5000),
+			CachePersist: getEnvBool("EMBEDDING_CACHE_PERSIST", true),
 		},
 		Upload: UploadConfig{
 			Dir:         getEnv("UPLOAD_DIR", "./uploads"),
 			MaxFileSize: 50 * 1024 * 1024, // 50MB
 		},
+		Ingestion: IngestionConfig{
+			Workers:         getEnvInt("INGEST_WORKERS", 2),
+			MaxAttempts:     getEnvInt("INGEST_MAX_ATTEMPTS", 3),
+			PollInterval:    getEnvDuration("INGEST_POLL_INTERVAL", 2*time.Second),
+			RetryBackoff:    getEnvDuration("INGEST_RETRY_BACKOFF", 30*time.Second),
+			RetryBackoffMax: getEnvDuration("INGEST_RETRY_BACKOFF_MAX", 10*time.Minute),
+			JobTimeout:      getEnvDuration("INGEST_JOB_TIMEOUT", 30*time.Minute),
+			StaleAfter:      getEnvDuration("INGEST_STALE_AFTER", 2*time.Minute),
+		},
+		Chunking: ChunkingConfig{
+			Size:    getEnvInt("CHUNK_SIZE", 500),
+			Overlap: getEnvInt("CHUNK_OVERLAP", 50),
+		},
+		Search: SearchConfig{
+			Mode:                getEnv("SEARCH_MODE", "hybrid"),
+			VectorWeight:        getEnvFloat("SEARCH_VECTOR_WEIGHT", 0.5),
+			SimilarityThreshold: getEnvFloat("SEARCH_SIMILARITY_THRESHOLD", 0.3),
+		},
+		Rerank: RerankConfig{
+			Provider:       getEnv("RERANK_PROVIDER", "none"),
+			APIBaseURL:     getEnv("RERANK_API_URL", ""),
+			APIKey:         getEnv("RERANK_API_KEY", ""),
+			Model:          getEnv("RERANK_MODEL", ""),
+			CandidateCount: getEnvInt("RERANK_CANDIDATES", 30),
+			TopN:           getEnvInt("RERANK_TOP_N", 10),
+			MinScore:       getEnvFloat("RERANK_MIN_SCORE", 0),
+		},
+		HTTP: HTTPConfig{
+			Timeout:             getEnvDuration("HTTP_TIMEOUT", 60*time.Second),
+			StreamTimeout:       getEnvDuration("HTTP_STREAM_TIMEOUT", 5*time.Minute),
+			MaxRetries:          getEnvInt("HTTP_MAX_RETRIES", 2),
+			RetryBackoff:        getEnvDuration("HTTP_RETRY_BACKOFF", 500*time.Millisecond),
+			RetryBackoffMax:     getEnvDuration("HTTP_RETRY_BACKOFF_MAX", 30*time.Second),
+			BreakerFailures:     getEnvInt("HTTP_BREAKER_FAILURES", 5),
+			BreakerCooldown:     getEnvDuration("HTTP_BREAKER_COOLDOWN", 30*time.Second),
+			MaxIdleConnsPerHost: getEnvInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 16),
+		},
+		Auth: AuthConfig{
+			Enabled: getEnvBool("AUTH_ENABLED", true),
+		},
+		RateLimit: RateLimitConfig{
+			Queries: RateBudget{
+				PerMinute: getEnvInt("RATE_LIMIT_QUERIES_PER_MINUTE", 60),
+				Burst:     getEnvInt("RATE_LIMIT_QUERY_BURST", 10),
+			},
+			Uploads: RateBudget{
+				PerMinute: getEnvInt("RATE_LIMIT_UPLOADS_PER_MINUTE", 5),
+				Burst:     getEnvInt("RATE_LIMIT_UPLOAD_BURST", 5),
+			},
+		},
+		Workspace: WorkspaceConfig{
+			MaxDocuments:    getEnvInt("WORKSPACE_MAX_DOCUMENTS", 0),
+			MaxStorageBytes: int64(getEnvInt("WORKSPACE_MAX_STORAGE_MB", 0)) << 20,
+		},
+		Grounding: GroundingConfig{
+			Provider:            getEnv("GROUNDING_PROVIDER", "none"),
+			APIBaseURL:          getEnv("GROUNDING_API_URL", ""),
+			APIKey:              getEnv("GROUNDING_API_KEY", ""),
+			Model:               getEnv("GROUNDING_MODEL", ""),
+			Action:              getEnv("GROUNDING_ACTION", "flag"),
+			MinScore:            getEnvFloat("GROUNDING_MIN_SCORE", 0.8),
+			EntailmentThreshold: getEnvFloat("GROUNDING_ENTAILMENT_THRESHOLD", 0.5),
+		},
 	}
 }
 
@@ -89,3 +323,55 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvOptionalFloat returns nil when key is unset or not a number
+func getEnvOptionalFloat(key string) *float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// getEnvList splits a comma-separated value, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -1,15 +1,16 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/pdf-rag-system/backend/internal/domain"
 	"github.com/pdf-rag-system/backend/pkg/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func InitDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
+// Open connects to the database without touching the schema
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName,
@@ -19,9 +20,26 @@ func InitDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	return db, nil
+}
 
-	// Auto migrate models
-	if err := db.AutoMigrate(&domain.Document{}, &domain.Chunk{}); err != nil {
+func InitDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.AutoMigrate {
+		return db, nil
+	}
+
+	// Apply pending migrations; replicas starting together wait on the
+	// migration lock instead of migrating concurrently
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.Up(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
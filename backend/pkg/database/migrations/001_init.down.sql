@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS query_history;
+DROP TABLE IF EXISTS chunks;
+DROP TABLE IF EXISTS documents;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -2,7 +2,7 @@
 CREATE EXTENSION IF NOT EXISTS vector;
 
 -- Documents table
-CREATE TABLE documents (
+CREATE TABLE IF NOT EXISTS documents (
     id VARCHAR(36) PRIMARY KEY,
     filename VARCHAR(255) NOT NULL,
     file_path VARCHAR(512) NOT NULL,
@@ -15,7 +15,7 @@ CREATE TABLE documents (
 );
 
 -- Chunks table
-CREATE TABLE chunks (
+CREATE TABLE IF NOT EXISTS chunks (
     id VARCHAR(36) PRIMARY KEY,
     document_id VARCHAR(36) NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
     content TEXT NOT NULL,
@@ -35,15 +35,15 @@ CREATE TABLE chunks (
 );
 
 -- Indexes for performance
-CREATE INDEX idx_chunks_document_id ON chunks(document_id);
-CREATE INDEX idx_chunks_page_number ON chunks(document_id, page_number);
-CREATE INDEX idx_chunks_has_bbox ON chunks(document_id, bbox_x1) WHERE bbox_x1 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_chunks_document_id ON chunks(document_id);
+CREATE INDEX IF NOT EXISTS idx_chunks_page_number ON chunks(document_id, page_number);
+CREATE INDEX IF NOT EXISTS idx_chunks_has_bbox ON chunks(document_id, bbox_x1) WHERE bbox_x1 IS NOT NULL;
 
--- Vector similarity search index (HNSW for fast approximate nearest neighbor search)
-CREATE INDEX idx_chunks_embedding ON chunks USING hnsw (embedding vector_cosine_ops);
+-- Vector similarity search indexes are created per embedding space by the
+-- server (see 010_embedding_spaces)
 
 -- Query history table (optional, for analytics)
-CREATE TABLE query_history (
+CREATE TABLE IF NOT EXISTS query_history (
     id SERIAL PRIMARY KEY,
     query TEXT NOT NULL,
     response TEXT,
@@ -62,8 +62,10 @@ END;
 $$ language 'plpgsql';
 
 -- Apply trigger to tables
+DROP TRIGGER IF EXISTS update_documents_updated_at ON documents;
 CREATE TRIGGER update_documents_updated_at BEFORE UPDATE ON documents
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+DROP TRIGGER IF EXISTS update_chunks_updated_at ON chunks;
 CREATE TRIGGER update_chunks_updated_at BEFORE UPDATE ON chunks
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
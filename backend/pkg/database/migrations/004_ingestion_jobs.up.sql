@@ -0,0 +1,25 @@
+-- Durable ingestion job queue
+CREATE TABLE IF NOT EXISTS ingestion_jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    document_id VARCHAR(36) NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- pending, running, succeeded, failed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    last_error TEXT,
+    -- Earliest time the job may be claimed (used for retry backoff)
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    -- Refreshed by the worker while running; stale jobs are requeued
+    heartbeat_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_jobs_document_id ON ingestion_jobs(document_id);
+CREATE INDEX IF NOT EXISTS idx_ingestion_jobs_claim ON ingestion_jobs(status, run_at);
+
+DROP TRIGGER IF EXISTS update_ingestion_jobs_updated_at ON ingestion_jobs;
+CREATE TRIGGER update_ingestion_jobs_updated_at BEFORE UPDATE ON ingestion_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS processing_stage,
+    DROP COLUMN IF EXISTS chunks_done,
+    DROP COLUMN IF EXISTS chunks_total,
+    DROP COLUMN IF EXISTS stage_started_at,
+    DROP COLUMN IF EXISTS eta,
+    DROP COLUMN IF EXISTS last_error;
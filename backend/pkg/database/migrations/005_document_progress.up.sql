@@ -0,0 +1,10 @@
+-- Ingestion progress on documents
+ALTER TABLE documents
+    -- queued, parsing, embedding, storing, done
+    ADD COLUMN IF NOT EXISTS processing_stage VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS chunks_done INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS chunks_total INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS stage_started_at TIMESTAMP,
+    -- Estimated completion time of the current stage
+    ADD COLUMN IF NOT EXISTS eta TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS last_error TEXT;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS failed_chunks;
+
+ALTER TABLE ingestion_jobs DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS error_code,
+    DROP COLUMN IF EXISTS error_message,
+    DROP COLUMN IF EXISTS skipped_chunks;
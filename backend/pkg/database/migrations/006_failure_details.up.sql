@@ -0,0 +1,35 @@
+-- Failure reasons and per-chunk error accounting
+ALTER TABLE documents
+    -- Machine-readable failure code, e.g. parse_failed, embedding_failed
+    ADD COLUMN IF NOT EXISTS error_code VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS error_message TEXT,
+    -- Chunks left out because their embedding failed (status 'partial')
+    ADD COLUMN IF NOT EXISTS skipped_chunks INTEGER DEFAULT 0;
+
+-- ingest or retry_failed
+ALTER TABLE ingestion_jobs ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'ingest';
+
+-- Chunks whose embedding failed, kept so they can be retried
+CREATE TABLE IF NOT EXISTS failed_chunks (
+    id VARCHAR(36) PRIMARY KEY,
+    document_id VARCHAR(36) NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    chunk_index INTEGER NOT NULL,
+    page_number INTEGER DEFAULT 0,
+    start_pos INTEGER,
+    end_pos INTEGER,
+    bbox_x1 FLOAT,
+    bbox_y1 FLOAT,
+    bbox_x2 FLOAT,
+    bbox_y2 FLOAT,
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_failed_chunks_document_id ON failed_chunks(document_id);
+
+DROP TRIGGER IF EXISTS update_failed_chunks_updated_at ON failed_chunks;
+CREATE TRIGGER update_failed_chunks_updated_at BEFORE UPDATE ON failed_chunks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+ALTER TABLE ingestion_jobs
+    DROP COLUMN IF EXISTS chunk_size,
+    DROP COLUMN IF EXISTS chunk_overlap,
+    DROP COLUMN IF EXISTS embedding_model;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS chunk_size,
+    DROP COLUMN IF EXISTS chunk_overlap,
+    DROP COLUMN IF EXISTS embedding_model;
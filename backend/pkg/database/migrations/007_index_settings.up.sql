@@ -0,0 +1,12 @@
+-- Chunking and embedding settings, so documents can be reindexed
+-- Settings the current chunks were built with
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS chunk_size INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS chunk_overlap INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100);
+
+-- Settings an ingest or reindex job builds the chunks with
+ALTER TABLE ingestion_jobs
+    ADD COLUMN IF NOT EXISTS chunk_size INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS chunk_overlap INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100);
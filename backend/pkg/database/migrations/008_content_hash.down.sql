@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_documents_alias_of;
+DROP INDEX IF EXISTS idx_documents_content_hash;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS content_hash,
+    DROP COLUMN IF EXISTS alias_of;
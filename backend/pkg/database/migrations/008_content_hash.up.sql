@@ -0,0 +1,9 @@
+-- Content-hash deduplication of uploads
+ALTER TABLE documents
+    -- SHA-256 of the uploaded file
+    ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64),
+    -- Set on duplicate uploads that reuse another document's chunks
+    ADD COLUMN IF NOT EXISTS alias_of VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS idx_documents_content_hash ON documents(content_hash);
+CREATE INDEX IF NOT EXISTS idx_documents_alias_of ON documents(alias_of);
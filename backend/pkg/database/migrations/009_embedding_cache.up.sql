@@ -0,0 +1,10 @@
+-- Persistent embedding cache, keyed by model and normalized text hash
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    model VARCHAR(100) NOT NULL,
+    -- SHA-256 of the text with whitespace collapsed
+    text_hash VARCHAR(64) NOT NULL,
+    -- No fixed dimension: different models may be cached side by side
+    embedding vector NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model, text_hash)
+);
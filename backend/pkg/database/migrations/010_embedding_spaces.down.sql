@@ -0,0 +1,24 @@
+-- Per-space indexes are named idx_chunks_embedding_<space id>
+DO $$
+DECLARE
+    idx RECORD;
+BEGIN
+    FOR idx IN
+        SELECT indexname FROM pg_indexes
+        WHERE tablename = 'chunks' AND indexname LIKE 'idx_chunks_embedding\_%'
+            AND indexname <> 'idx_chunks_embedding_space_id'
+    LOOP
+        EXECUTE format('DROP INDEX IF EXISTS %I', idx.indexname);
+    END LOOP;
+END $$;
+
+ALTER TABLE ingestion_jobs DROP COLUMN IF EXISTS embedding_space_id;
+ALTER TABLE failed_chunks DROP COLUMN IF EXISTS embedding_space_id;
+ALTER TABLE chunks DROP COLUMN IF EXISTS embedding_space_id;
+
+DROP TABLE IF EXISTS embedding_spaces;
+
+-- Only embeddings of the original fixed dimension can be kept
+DELETE FROM chunks WHERE vector_dims(embedding) <> 768;
+ALTER TABLE chunks ALTER COLUMN embedding TYPE vector(768);
+CREATE INDEX IF NOT EXISTS idx_chunks_embedding ON chunks USING hnsw (embedding vector_cosine_ops);
@@ -0,0 +1,29 @@
+-- Embedding spaces: several embedding models side by side
+CREATE TABLE IF NOT EXISTS embedding_spaces (
+    id VARCHAR(36) PRIMARY KEY,
+    model VARCHAR(100) NOT NULL,
+    version VARCHAR(50),
+    dimension INTEGER NOT NULL,
+    -- active (used by queries, exactly one), building or retired
+    status VARCHAR(20) NOT NULL DEFAULT 'building',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS update_embedding_spaces_updated_at ON embedding_spaces;
+CREATE TRIGGER update_embedding_spaces_updated_at BEFORE UPDATE ON embedding_spaces
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- The embedding column no longer has a fixed dimension. Each space gets a
+-- partial HNSW index on embedding::vector(<dimension>), created by the server.
+DROP INDEX IF EXISTS idx_chunks_embedding;
+ALTER TABLE chunks ALTER COLUMN embedding TYPE vector;
+
+-- Space each embedding belongs to; existing chunks are assigned to the first
+-- space when the server creates it
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS embedding_space_id VARCHAR(36);
+ALTER TABLE failed_chunks ADD COLUMN IF NOT EXISTS embedding_space_id VARCHAR(36);
+ALTER TABLE ingestion_jobs ADD COLUMN IF NOT EXISTS embedding_space_id VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS idx_chunks_embedding_space_id ON chunks(embedding_space_id);
+CREATE INDEX IF NOT EXISTS idx_failed_chunks_embedding_space_id ON failed_chunks(embedding_space_id);
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_query_history_created_at;
+
+ALTER TABLE query_history ALTER COLUMN response_time_ms TYPE INTEGER;
+
+ALTER TABLE query_history
+    DROP COLUMN IF EXISTS rewritten_query,
+    DROP COLUMN IF EXISTS session_id,
+    DROP COLUMN IF EXISTS document_ids,
+    DROP COLUMN IF EXISTS search_mode,
+    DROP COLUMN IF EXISTS scores,
+    DROP COLUMN IF EXISTS result_count,
+    DROP COLUMN IF EXISTS error,
+    DROP COLUMN IF EXISTS embed_ms,
+    DROP COLUMN IF EXISTS search_ms,
+    DROP COLUMN IF EXISTS llm_ms,
+    DROP COLUMN IF EXISTS prompt_tokens,
+    DROP COLUMN IF EXISTS completion_tokens,
+    DROP COLUMN IF EXISTS total_tokens;
@@ -0,0 +1,24 @@
+-- Per-query analytics on the query_history table from 001_init
+ALTER TABLE query_history
+    ADD COLUMN rewritten_query TEXT,
+    ADD COLUMN session_id VARCHAR(36),
+    ADD COLUMN document_ids JSONB,
+    -- vector, keyword or hybrid
+    ADD COLUMN search_mode VARCHAR(20),
+    -- Score breakdown of the cited chunks
+    ADD COLUMN scores JSONB,
+    -- Number of citations returned; 0 means nothing relevant was found
+    ADD COLUMN result_count INTEGER DEFAULT 0,
+    -- Set when the query failed
+    ADD COLUMN error TEXT,
+    -- Latency per stage; search covers retrieval, fusion and reranking
+    ADD COLUMN embed_ms BIGINT DEFAULT 0,
+    ADD COLUMN search_ms BIGINT DEFAULT 0,
+    ADD COLUMN llm_ms BIGINT DEFAULT 0,
+    ADD COLUMN prompt_tokens INTEGER DEFAULT 0,
+    ADD COLUMN completion_tokens INTEGER DEFAULT 0,
+    ADD COLUMN total_tokens INTEGER DEFAULT 0;
+
+ALTER TABLE query_history ALTER COLUMN response_time_ms TYPE BIGINT;
+
+CREATE INDEX idx_query_history_created_at ON query_history(created_at);
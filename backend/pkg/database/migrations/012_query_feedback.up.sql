@@ -0,0 +1,17 @@
+-- User feedback on recorded query results
+CREATE TABLE query_feedback (
+    id VARCHAR(36) PRIMARY KEY,
+    query_id BIGINT NOT NULL REFERENCES query_history(id) ON DELETE CASCADE,
+    -- up, down or NULL
+    rating VARCHAR(10),
+    comment TEXT,
+    -- Per-citation relevance marks: [{"chunk_id": "...", "relevant": true}]
+    citations JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_query_feedback_query_id ON query_feedback(query_id);
+
+CREATE TRIGGER update_query_feedback_updated_at BEFORE UPDATE ON query_feedback
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
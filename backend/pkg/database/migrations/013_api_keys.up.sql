@@ -0,0 +1,18 @@
+-- API keys; only the SHA-256 of a key is stored
+CREATE TABLE api_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- First characters of the key, to recognize it in listings
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    -- read, write or admin
+    scope VARCHAR(10) NOT NULL,
+    -- Per-key rate limits (requests per minute); NULL uses the configured default
+    query_rate_limit INTEGER,
+    upload_rate_limit INTEGER,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys(key_hash);
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_api_keys_workspace_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_query_history_workspace_id;
+DROP INDEX IF EXISTS idx_sessions_workspace_id;
+DROP INDEX IF EXISTS idx_chunks_workspace_id;
+DROP INDEX IF EXISTS idx_documents_workspace_id;
+
+ALTER TABLE query_history DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE sessions DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE chunks DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE documents DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,36 @@
+-- Workspaces (tenants) isolating documents, chunks, sessions and queries
+CREATE TABLE workspaces (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- Quotas; NULL uses the configured default, 0 means unlimited
+    max_documents INTEGER,
+    max_storage_bytes BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_workspaces_updated_at BEFORE UPDATE ON workspaces
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing data moves into the default workspace
+INSERT INTO workspaces (id, name) VALUES ('default', 'Default');
+
+-- The default only backfills existing rows; new rows must name their workspace
+ALTER TABLE documents ADD COLUMN workspace_id VARCHAR(36) NOT NULL DEFAULT 'default' REFERENCES workspaces(id);
+ALTER TABLE chunks ADD COLUMN workspace_id VARCHAR(36) NOT NULL DEFAULT 'default' REFERENCES workspaces(id);
+ALTER TABLE sessions ADD COLUMN workspace_id VARCHAR(36) NOT NULL DEFAULT 'default' REFERENCES workspaces(id);
+ALTER TABLE query_history ADD COLUMN workspace_id VARCHAR(36) NOT NULL DEFAULT 'default' REFERENCES workspaces(id);
+ALTER TABLE documents ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE chunks ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE sessions ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE query_history ALTER COLUMN workspace_id DROP DEFAULT;
+
+CREATE INDEX idx_documents_workspace_id ON documents(workspace_id, upload_time DESC);
+CREATE INDEX idx_chunks_workspace_id ON chunks(workspace_id);
+CREATE INDEX idx_sessions_workspace_id ON sessions(workspace_id, updated_at DESC);
+CREATE INDEX idx_query_history_workspace_id ON query_history(workspace_id, created_at);
+
+-- Keys bound to a workspace can only act in it; NULL is a key for all
+-- workspaces, which picks one per request
+ALTER TABLE api_keys ADD COLUMN workspace_id VARCHAR(36) REFERENCES workspaces(id) ON DELETE CASCADE;
+CREATE INDEX idx_api_keys_workspace_id ON api_keys(workspace_id);
@@ -0,0 +1,25 @@
+-- Named collections of documents within a workspace, for searching a
+-- group of documents without listing their IDs
+CREATE TABLE collections (
+    id VARCHAR(36) PRIMARY KEY,
+    workspace_id VARCHAR(36) NOT NULL REFERENCES workspaces(id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_collections_workspace_name ON collections(workspace_id, name);
+
+CREATE TRIGGER update_collections_updated_at BEFORE UPDATE ON collections
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Membership goes away with the collection or the document
+CREATE TABLE collection_documents (
+    collection_id VARCHAR(36) NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    document_id VARCHAR(36) NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (collection_id, document_id)
+);
+
+CREATE INDEX idx_collection_documents_document_id ON collection_documents(document_id);
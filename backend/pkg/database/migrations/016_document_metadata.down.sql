@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_documents_author;
+DROP INDEX IF EXISTS idx_documents_metadata;
+DROP INDEX IF EXISTS idx_documents_tags;
+
+ALTER TABLE documents DROP COLUMN IF EXISTS metadata;
+ALTER TABLE documents DROP COLUMN IF EXISTS tags;
+ALTER TABLE documents DROP COLUMN IF EXISTS creation_date;
+ALTER TABLE documents DROP COLUMN IF EXISTS author;
+ALTER TABLE documents DROP COLUMN IF EXISTS title;
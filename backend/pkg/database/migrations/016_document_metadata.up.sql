@@ -0,0 +1,12 @@
+-- Descriptive fields read from the PDF info dictionary, plus user-assigned
+-- tags and key/value metadata for filtering retrieval
+ALTER TABLE documents ADD COLUMN title VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE documents ADD COLUMN author VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE documents ADD COLUMN creation_date TIMESTAMP;
+ALTER TABLE documents ADD COLUMN tags JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE documents ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+-- Filters use containment (@>), which jsonb_path_ops indexes
+CREATE INDEX idx_documents_tags ON documents USING gin (tags jsonb_path_ops);
+CREATE INDEX idx_documents_metadata ON documents USING gin (metadata jsonb_path_ops);
+CREATE INDEX idx_documents_author ON documents (workspace_id, LOWER(author));
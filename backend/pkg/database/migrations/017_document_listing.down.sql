@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_documents_list_file_size;
+DROP INDEX IF EXISTS idx_documents_list_filename;
+DROP INDEX IF EXISTS idx_documents_list_upload_time;
@@ -0,0 +1,5 @@
+-- Keyset pagination orders by the sort field and then by ID; one index per
+-- sort field keeps every page an index range scan
+CREATE INDEX idx_documents_list_upload_time ON documents (workspace_id, upload_time, id);
+CREATE INDEX idx_documents_list_filename ON documents (workspace_id, filename, id);
+CREATE INDEX idx_documents_list_file_size ON documents (workspace_id, file_size, id);
@@ -0,0 +1 @@
+ALTER TABLE query_history DROP COLUMN IF EXISTS uncited;
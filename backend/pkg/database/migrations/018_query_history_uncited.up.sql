@@ -0,0 +1,3 @@
+-- Set when the answer cited none of its sources; citations then lists every
+-- source the answer was given, which must not count as citations
+ALTER TABLE query_history ADD COLUMN uncited BOOLEAN NOT NULL DEFAULT false;
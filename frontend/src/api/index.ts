@@ -1,24 +1,84 @@
 import axios from 'axios'
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080'
+const WORKSPACE_ID = import.meta.env.VITE_WORKSPACE_ID || ''
+
+// The API key is entered by the user and kept for the browser tab only, so
+// no key is ever built into the bundle
+const API_KEY_STORAGE = 'pdf-rag-api-key'
+
+export function getApiKey(): string {
+  return sessionStorage.getItem(API_KEY_STORAGE) || ''
+}
+
+export function setApiKey(key: string) {
+  if (key) {
+    sessionStorage.setItem(API_KEY_STORAGE, key)
+  } else {
+    sessionStorage.removeItem(API_KEY_STORAGE)
+  }
+}
+
+let unauthorizedHandler: (() => void) | null = null
+
+// onUnauthorized registers what to do when the API rejects the key, such as
+// asking the user for one
+export function onUnauthorized(handler: () => void) {
+  unauthorizedHandler = handler
+}
 
 const apiClient = axios.create({
   baseURL: `${API_BASE_URL}/api/v1`,
   headers: {
-    'Content-Type': 'application/json'
+    'Content-Type': 'application/json',
+    ...(WORKSPACE_ID ? { 'X-Workspace-ID': WORKSPACE_ID } : {})
   }
 })
 
+apiClient.interceptors.request.use((config) => {
+  const key = getApiKey()
+  if (key) config.headers.Authorization = `Bearer ${key}`
+  return config
+})
+
+apiClient.interceptors.response.use(undefined, (error) => {
+  if (error.response?.status === 401) unauthorizedHandler?.()
+  return Promise.reject(error)
+})
+
+// fetchObjectUrl loads a file through the API client, so the key travels in
+// the Authorization header rather than the URL. Callers revoke the returned
+// URL with URL.revokeObjectURL when done with it.
+async function fetchObjectUrl(url: string, params?: Record<string, string | number>): Promise<string> {
+  const response = await apiClient.get(url, { params, responseType: 'blob' })
+  return URL.createObjectURL(response.data)
+}
+
 export default {
   // Documents
-  async getDocuments() {
-    return apiClient.get('/documents')
+  async getDocuments(params: {
+    status?: string
+    filename?: string
+    tag?: string[]
+    min_size?: number
+    max_size?: number
+    uploaded_after?: string
+    uploaded_before?: string
+    sort?: 'upload_time' | 'filename' | 'file_size'
+    order?: 'asc' | 'desc'
+    cursor?: string
+    limit?: number
+  } = {}) {
+    // Repeat tag=... rather than axios' default tag[]=...
+    return apiClient.get('/documents', { params, paramsSerializer: { indexes: null } })
   },
 
-  async uploadDocument(file: File) {
+  async uploadDocument(file: File, labels: { tags?: string[]; metadata?: Record<string, string> } = {}) {
     console.log('[API] Uploading document:', file.name, 'size:', file.size)
     const formData = new FormData()
     formData.append('file', file)
+    labels.tags?.forEach((tag) => formData.append('tags', tag))
+    if (labels.metadata) formData.append('metadata', JSON.stringify(labels.metadata))
 
     console.log('[API] FormData created, sending POST request...')
     try {
@@ -45,13 +105,64 @@ export default {
     return apiClient.delete(`/documents/${id}`)
   },
 
+  async updateDocument(id: string, data: {
+    title?: string
+    author?: string
+    creation_date?: string
+    tags?: string[]
+    metadata?: Record<string, string | null>
+  }) {
+    return apiClient.patch(`/documents/${id}`, data)
+  },
+
   async getDocumentFileUrl(id: string): Promise<string> {
-    // Return URL to PDF file
-    return `${API_BASE_URL}/api/v1/documents/${id}/file`
+    return fetchObjectUrl(`/documents/${id}/file`)
+  },
+
+  async getPageImageUrl(id: string, page: number, bbox?: { x1: number; y1: number; x2: number; y2: number } | null): Promise<string> {
+    const params = bbox ? { bbox_x1: bbox.x1, bbox_y1: bbox.y1, bbox_x2: bbox.x2, bbox_y2: bbox.y2 } : undefined
+    return fetchObjectUrl(`/documents/${id}/page/${page}/image`, params)
+  },
+
+  // Collections
+  async getCollections() {
+    return apiClient.get('/collections')
+  },
+
+  async getCollection(id: string) {
+    return apiClient.get(`/collections/${id}`)
+  },
+
+  async createCollection(data: { name: string; description?: string; document_ids?: string[] }) {
+    return apiClient.post('/collections', data)
+  },
+
+  async deleteCollection(id: string) {
+    return apiClient.delete(`/collections/${id}`)
+  },
+
+  async addToCollection(id: string, documentIds: string[]) {
+    return apiClient.post(`/collections/${id}/documents`, { document_ids: documentIds })
+  },
+
+  async removeFromCollection(id: string, documentIds: string[]) {
+    return apiClient.delete(`/collections/${id}/documents`, { data: { document_ids: documentIds } })
   },
 
   // Chat
-  async query(data: { query: string; document_ids: string[] }) {
+  async query(data: {
+    query: string
+    document_ids?: string[]
+    collection_ids?: string[]
+    all_documents?: boolean
+    filters?: {
+      tags?: string[]
+      author?: string
+      created_after?: string
+      created_before?: string
+      metadata?: Record<string, string>
+    }
+  }) {
     return apiClient.post('/chat/query', data)
   }
 }